@@ -0,0 +1,75 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import "time"
+
+// The combinators below take ascending iterators, such as those returned by RRule.Iterator
+// and Set.Iterator, and merge them lazily into a new ascending iterator without duplicates,
+// the same way Set.Iterator merges its RRULE and RDATEs.
+// Like Set.Iterator, they are only supported second precision.
+// Combining infinite iterators that never share a time, with Intersection for example,
+// never yields and never returns.
+
+// Union returns an iterator over the times yielded by any of the iterators.
+func Union(iterators ...Next) Next {
+	return combine(iterators, func(n int, first bool) bool { return true }, nil)
+}
+
+// Intersection returns an iterator over the times yielded by all of the iterators.
+func Intersection(iterators ...Next) Next {
+	k := len(iterators)
+	return combine(iterators, func(n int, first bool) bool { return n == k }, func(m *merger) bool {
+		for id := 0; id < k; id++ {
+			if !m.alive(id) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Difference returns an iterator over the times yielded by base
+// and by none of the other iterators.
+func Difference(base Next, iterators ...Next) Next {
+	return combine(append([]Next{base}, iterators...), func(n int, first bool) bool { return first && n == 1 }, func(m *merger) bool {
+		return !m.alive(0)
+	})
+}
+
+// SymmetricDifference returns an iterator over the times yielded by an odd number of the iterators,
+// that is by exactly one of them when there are two iterators.
+func SymmetricDifference(iterators ...Next) Next {
+	return combine(iterators, func(n int, first bool) bool { return n%2 == 1 }, nil)
+}
+
+// combine merges iterators and yields the distinct times for which keep returns true,
+// given the number of iterators yielding the time and whether the first one does.
+// It stops early once done, if not nil, reports that no more time can be kept.
+func combine(iterators []Next, keep func(n int, first bool) bool, done func(m *merger) bool) Next {
+	m := merger{}
+	for _, next := range iterators {
+		m.add(next, SourceRule)
+	}
+	seen := make([]bool, len(iterators))
+	var ids []int
+	return func() (time.Time, bool) {
+		for !m.empty() && (done == nil || !done(&m)) {
+			dt := m.peek()
+			for _, id := range ids {
+				seen[id] = false
+			}
+			ids = ids[:0]
+			for !m.empty() && m.peek().Equal(dt) {
+				if item := m.pop(); !seen[item.id] {
+					seen[item.id] = true
+					ids = append(ids, item.id)
+				}
+			}
+			if keep(len(ids), seen[0]) {
+				return dt, true
+			}
+		}
+		return time.Time{}, false
+	}
+}
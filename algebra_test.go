@@ -0,0 +1,106 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func algebraRules() (daily, weekly Next) {
+	r1, _ := NewRRule(ROption{Freq: DAILY, Count: 10, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	r2, _ := NewRRule(ROption{Freq: WEEKLY, Byweekday: []Weekday{TU, TH},
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	return r1.Iterator(), r2.Iterator()
+}
+
+func TestUnion(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: DAILY, Count: 3, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	set := Set{}
+	set.RDate(time.Date(1997, 9, 1, 9, 0, 0, 0, time.UTC))
+	set.RDate(time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC))
+	value := all(Union(r.Iterator(), set.Iterator()))
+	want := []time.Time{
+		time.Date(1997, 9, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC),
+	}
+	if !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+	if _, ok := Union()(); ok {
+		t.Error("the union of nothing should be empty")
+	}
+}
+
+func TestIntersection(t *testing.T) {
+	// The weekly rule is infinite, the intersection stops with the daily rule.
+	value := all(Intersection(algebraRules()))
+	want := []time.Time{
+		time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 9, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 11, 9, 0, 0, 0, time.UTC),
+	}
+	if !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
+
+func TestDifference(t *testing.T) {
+	daily, weekly := algebraRules()
+	value := all(Difference(daily, weekly))
+	want := []time.Time{
+		time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 5, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 6, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 8, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 10, 9, 0, 0, 0, time.UTC),
+	}
+	if !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
+
+func TestSymmetricDifference(t *testing.T) {
+	daily, weekly := algebraRules()
+	value := between(SymmetricDifference(daily, weekly),
+		time.Date(1997, 9, 9, 0, 0, 0, 0, time.UTC), time.Date(1997, 9, 19, 0, 0, 0, 0, time.UTC), false)
+	want := []time.Time{
+		time.Date(1997, 9, 10, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 16, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 18, 9, 0, 0, 0, time.UTC),
+	}
+	if !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+
+	// A time yielded by all three iterators is kept.
+	r, _ := NewRRule(ROption{Freq: DAILY, Count: 1, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	daily, weekly = algebraRules()
+	if v, _ := SymmetricDifference(daily, weekly, r.Iterator())(); !v.Equal(time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("get %v", v)
+	}
+}
+
+func BenchmarkUnion(b *testing.B) {
+	for _, k := range []int{2, 16, 128} {
+		rules := make([]*RRule, k)
+		for i := range rules {
+			rules[i], _ = NewRRule(ROption{Freq: HOURLY, Interval: k, Count: 10000 / k,
+				Dtstart: time.Date(2000, 1, 1, i, 0, 0, 0, time.UTC)})
+		}
+		b.Run(fmt.Sprintf("%d rules", k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				iterators := make([]Next, k)
+				for j, r := range rules {
+					iterators[j] = r.Iterator()
+				}
+				_ = all(Union(iterators...))
+			}
+		})
+	}
+}
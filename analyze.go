@@ -0,0 +1,194 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"fmt"
+)
+
+// The Gregorian calendar repeats itself, weekdays included, every 400 years.
+// These are the number of periods of each frequency in such a cycle.
+const (
+	cycleYears  = 400
+	cycleMonths = cycleYears * 12
+	cycleDays   = 146097
+	cycleWeeks  = cycleDays / 7
+)
+
+// subDailyPeriods bounds the simulation of HOURLY, MINUTELY and SECONDLY rules,
+// whose calendar cycle is too long to be scanned exhaustively.
+const subDailyPeriods = 1 << 20
+
+// maxMonthDays is the largest number of days of each month, leap years included.
+var maxMonthDays = [...]int{0, 31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// UnsatisfiableError is returned by Analyze for rules that can never produce an occurrence.
+type UnsatisfiableError struct {
+	Reason string
+}
+
+func (e *UnsatisfiableError) Error() string {
+	return "rule never produces occurrences: " + e.Reason
+}
+
+// Analyze checks in advance whether the rule described by arg can produce at least one occurrence.
+// It returns the error NewRRule would return for invalid options, an *UnsatisfiableError
+// if the rule provably never produces an occurrence, and nil otherwise.
+//
+// Conflicting BYxxx parts, such as BYMONTHDAY=31 with BYMONTH=4, and time parts that
+// INTERVAL never reaches are detected structurally. Other rules are simulated: YEARLY to DAILY
+// rules are expanded over a full 400-year calendar cycle (or until UNTIL), which proves they
+// are unsatisfiable if nothing is found. HOURLY, MINUTELY and SECONDLY rules are only reported
+// when they are exhausted within a bounded number of periods.
+//
+// Options are those of NewRRule.
+func Analyze(arg ROption, opts ...Option) error {
+	clock := newOptions(opts).clock
+	if err := validateBounds(arg); err != nil {
+		return err
+	}
+	if reason := conflictingParts(arg); reason != "" {
+		return &UnsatisfiableError{Reason: reason}
+	}
+
+	arg.Count = 0
+	r := buildRRule(arg, clock)
+	if r.freq >= HOURLY {
+		if reason := unreachableTime(&r); reason != "" {
+			return &UnsatisfiableError{Reason: reason}
+		}
+		// Sub-daily rules can only match days a DAILY rule with the same
+		// day filters matches.
+		days := arg
+		days.Freq, days.Interval = DAILY, 1
+		days.Bysetpos, days.Byhour, days.Byminute, days.Bysecond = nil, nil, nil, nil
+		d := buildRRule(days, clock)
+		if matched, exhausted := simulate(&d, cycleDays+1); !matched && exhausted {
+			return &UnsatisfiableError{Reason: noMatchReason(arg, "day")}
+		}
+		if matched, exhausted := simulate(&r, subDailyPeriods); !matched && exhausted {
+			return &UnsatisfiableError{Reason: noMatchReason(arg, "candidate")}
+		}
+		return nil
+	}
+
+	cycle := map[Frequency]int{YEARLY: cycleYears, MONTHLY: cycleMonths, WEEKLY: cycleWeeks, DAILY: cycleDays}[r.freq]
+	matched, exhausted := simulate(&r, cycle+1)
+	// BYEASTER does not follow the 400-year cycle, a full cycle without match proves nothing.
+	if !matched && (exhausted || len(r.byeaster) == 0) {
+		return &UnsatisfiableError{Reason: noMatchReason(arg, "period")}
+	}
+	return nil
+}
+
+// simulate expands r for at most periods candidate periods.
+// It reports whether an occurrence was found, and whether the recurrence
+// was exhausted, by UNTIL or MAXYEAR, rather than stopped by the budget.
+func simulate(r *RRule, periods int) (matched, exhausted bool) {
+	r.maxPeriods = periods
+	next, err := r.IteratorContext(context.Background())
+	if _, ok := next(); ok {
+		return true, false
+	}
+	return false, err() == nil
+}
+
+func noMatchReason(arg ROption, what string) string {
+	if !arg.Until.IsZero() {
+		return fmt.Sprintf("no %s between DTSTART and UNTIL matches the rule", what)
+	}
+	return fmt.Sprintf("no %s in a full calendar cycle matches the rule", what)
+}
+
+// conflictingParts looks for BYxxx parts that exclude each other on any year.
+func conflictingParts(arg ROption) string {
+	if len(arg.Bymonth) == 0 {
+		return ""
+	}
+
+	if len(arg.Bymonthday) != 0 {
+		found := false
+		for _, month := range arg.Bymonth {
+			for _, mday := range arg.Bymonthday {
+				if mday <= maxMonthDays[month] && -mday <= maxMonthDays[month] {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return fmt.Sprintf("BYMONTHDAY=%s never falls in BYMONTH=%s",
+				joinInts(arg.Bymonthday), joinInts(arg.Bymonth))
+		}
+	}
+
+	if len(arg.Byyearday) != 0 {
+		found := false
+		for _, yday := range arg.Byyearday {
+			for _, yearlen := range []int{365, 366} {
+				if yday > yearlen || -yday > yearlen {
+					continue
+				}
+				day := yday
+				if day < 0 {
+					day += yearlen + 1
+				}
+				mmask := M365MASK
+				if yearlen == 366 {
+					mmask = M366MASK
+				}
+				if contains(arg.Bymonth, mmask[day-1]) {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return fmt.Sprintf("BYYEARDAY=%s never falls in BYMONTH=%s",
+				joinInts(arg.Byyearday), joinInts(arg.Bymonth))
+		}
+	}
+	return ""
+}
+
+// unreachableTime checks that stepping INTERVAL hours, minutes or seconds
+// from DTSTART reaches at least one time of day allowed by BYHOUR, BYMINUTE and BYSECOND.
+func unreachableTime(r *RRule) string {
+	var unit, dayLen int
+	switch r.freq {
+	case HOURLY:
+		unit, dayLen = 3600, 24
+	case MINUTELY:
+		unit, dayLen = 60, 1440
+	default:
+		unit, dayLen = 1, 86400
+	}
+	// Steps reach every time of day congruent to DTSTART modulo gcd(INTERVAL, dayLen).
+	g := gcd(r.interval, dayLen)
+	h, m, s := r.dtstart.Clock()
+	start := (h*3600 + m*60 + s) / unit
+
+	orAll := func(values []int, n int) []int {
+		if len(values) != 0 {
+			return values
+		}
+		return rang(0, n)
+	}
+	for _, hour := range orAll(r.byhour, 24) {
+		for _, minute := range orAll(r.byminute, 60) {
+			for _, second := range orAll(r.bysecond, 60) {
+				if pymod((hour*3600+minute*60+second)/unit-start, g) == 0 {
+					return ""
+				}
+			}
+		}
+	}
+	return fmt.Sprintf("INTERVAL=%d never reaches the BYHOUR, BYMINUTE and BYSECOND parts from DTSTART %s",
+		r.interval, r.dtstart.Format("15:04:05"))
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
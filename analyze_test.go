@@ -0,0 +1,61 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAnalyze(t *testing.T) {
+	dtstart := time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		name          string
+		option        ROption
+		unsatisfiable bool
+	}{
+		{"daily", ROption{Freq: DAILY, Dtstart: dtstart}, false},
+		{"leap day", ROption{Freq: YEARLY, Bymonth: []int{2}, Bymonthday: []int{29}, Dtstart: dtstart}, false},
+		{"april 31st", ROption{Freq: YEARLY, Bymonth: []int{4}, Bymonthday: []int{31}, Dtstart: dtstart}, true},
+		{"february 30th", ROption{Freq: MONTHLY, Bymonth: []int{2}, Bymonthday: []int{30, -30}, Dtstart: dtstart}, true},
+		{"yearday 366 before december", ROption{Freq: YEARLY, Byyearday: []int{366}, Bymonth: []int{1, 6, 11}, Dtstart: dtstart}, true},
+		{"yearday -1 in december", ROption{Freq: YEARLY, Byyearday: []int{-1}, Bymonth: []int{12}, Dtstart: dtstart}, false},
+		{"yearday -335 in february of leap years", ROption{Freq: YEARLY, Byyearday: []int{-335}, Bymonth: []int{2}, Dtstart: dtstart}, false},
+		{"leap day every 4 years from a non leap year", ROption{Freq: YEARLY, Interval: 4, Bymonth: []int{2}, Bymonthday: []int{29}, Dtstart: dtstart}, true},
+		{"week 53 before until", ROption{Freq: YEARLY, Byweekno: []int{53}, Byweekday: []Weekday{MO}, Dtstart: dtstart,
+			Until: time.Date(1998, 6, 1, 0, 0, 0, 0, time.UTC)}, true},
+		{"week 53", ROption{Freq: YEARLY, Byweekno: []int{53}, Byweekday: []Weekday{MO}, Dtstart: dtstart}, false},
+		{"friday 13th", ROption{Freq: MONTHLY, Byweekday: []Weekday{FR}, Bymonthday: []int{13}, Dtstart: dtstart}, false},
+		{"5th monday of february", ROption{Freq: YEARLY, Bymonth: []int{2}, Byweekday: []Weekday{MO.Nth(5)}, Dtstart: dtstart}, false},
+		{"weekly interval 7 days on another weekday", ROption{Freq: DAILY, Interval: 7, Byweekday: []Weekday{MO}, Dtstart: dtstart}, true},
+		{"easter", ROption{Freq: YEARLY, Byeaster: []int{0}, Dtstart: dtstart}, false},
+		{"unreachable hour", ROption{Freq: HOURLY, Interval: 24, Byhour: []int{5}, Dtstart: dtstart}, true},
+		{"reachable hour", ROption{Freq: HOURLY, Interval: 4, Byhour: []int{5}, Dtstart: dtstart}, false},
+		{"unreachable second", ROption{Freq: SECONDLY, Interval: 60, Bysecond: []int{30}, Dtstart: dtstart}, true},
+		{"minutely on april 31st", ROption{Freq: MINUTELY, Bymonth: []int{4}, Bymonthday: []int{31}, Dtstart: dtstart}, true},
+		{"hourly on impossible days", ROption{Freq: HOURLY, Bymonth: []int{4}, Byyearday: []int{1}, Dtstart: dtstart}, true},
+		{"secondly until", ROption{Freq: SECONDLY, Byminute: []int{30}, Dtstart: dtstart,
+			Until: time.Date(1997, 9, 2, 9, 10, 0, 0, time.UTC)}, true},
+	} {
+		err := Analyze(c.option)
+		var e *UnsatisfiableError
+		if c.unsatisfiable && !errors.As(err, &e) {
+			t.Errorf("%s: get %v, want an *UnsatisfiableError", c.name, err)
+		}
+		if !c.unsatisfiable && err != nil {
+			t.Errorf("%s: get %v, want nil", c.name, err)
+		}
+		if r, _ := NewRRule(c.option); !c.unsatisfiable && r.After(dtstart, true).IsZero() {
+			t.Errorf("%s: satisfiable rule has no occurrence", c.name)
+		}
+	}
+}
+
+func TestAnalyzeInvalid(t *testing.T) {
+	err := Analyze(ROption{Freq: MONTHLY, Bysetpos: []int{0}})
+	var e *UnsatisfiableError
+	if err == nil || errors.As(err, &e) {
+		t.Errorf("get %v, want a bounds error", err)
+	}
+}
@@ -0,0 +1,47 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import "time"
+
+// Clock tells the current time. The library reads it where it would otherwise
+// read the system clock, such as the default DTSTART of a rule, so that tests
+// and replays can freeze it.
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Option configures how a rule is built or parsed, see NewRRule and StrToRRule.
+type Option func(*options)
+
+type options struct {
+	clock  Clock
+	strict bool
+}
+
+// WithClock sets the clock of the rule, the system clock by default or if clock is nil.
+// The clock is kept by the rule, for example when DTSTART or UNTIL is changed.
+func WithClock(clock Clock) Option {
+	return func(o *options) { o.clock = clock }
+}
+
+// WithStrict makes NewRRule reject the rules for which Lint reports an Error,
+// such as COUNT and UNTIL together. Warnings are not rejected.
+func WithStrict() Option {
+	return func(o *options) { o.strict = true }
+}
+
+func newOptions(opts []Option) options {
+	o := options{clock: systemClock{}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.clock == nil {
+		o.clock = systemClock{}
+	}
+	return o
+}
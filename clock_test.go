@@ -0,0 +1,64 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time { return time.Time(c) }
+
+func TestWithClock(t *testing.T) {
+	clock := fixedClock(time.Date(2024, 1, 1, 9, 30, 15, 0, time.UTC))
+	r, _ := NewRRule(ROption{Freq: DAILY, Count: 2}, WithClock(clock))
+	want := []time.Time{time.Date(2024, 1, 1, 9, 30, 15, 0, time.UTC), time.Date(2024, 1, 2, 9, 30, 15, 0, time.UTC)}
+	if value := r.All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+
+	// The clock is kept when the rule is rebuilt.
+	r.Until(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC))
+	if value := r.GetDTStart(); !value.Equal(want[0]) {
+		t.Errorf("get %v, want %v", value, want[0])
+	}
+}
+
+func TestNilClock(t *testing.T) {
+	before := time.Now().Truncate(time.Second)
+	r, err := NewRRule(ROption{Freq: DAILY, Count: 1}, WithClock(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := r.GetDTStart(); value.Before(before) {
+		t.Errorf("get %v, want the current time", value)
+	}
+
+	// A rule that was not built by NewRRule falls back to the system clock when rebuilt.
+	r = &RRule{}
+	r.Until(time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC))
+	if value := r.GetDTStart(); value.Before(before) {
+		t.Errorf("get %v, want the current time", value)
+	}
+}
+
+func TestStrToRRuleWithClock(t *testing.T) {
+	clock := fixedClock(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	r, err := StrToRRule("FREQ=WEEKLY;COUNT=1", WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := r.GetDTStart(); !value.Equal(time.Time(clock)) {
+		t.Errorf("get %v, want %v", value, time.Time(clock))
+	}
+
+	set, err := StrToRRuleSet("RRULE:FREQ=DAILY;COUNT=1", WithClock(clock))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := set.All(); len(value) != 1 || !value[0].Equal(time.Time(clock)) {
+		t.Errorf("get %v, want %v", value, time.Time(clock))
+	}
+}
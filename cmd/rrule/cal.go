@@ -0,0 +1,69 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// calendar prints the grid of a month, marking with * the days that have occurrences
+// in the time zone, and lists the occurrences below it.
+func calendar(c *env, args []string) error {
+	month := c.flags.String("month", "", "month to print as YYYY-MM, the current month by default")
+	if err := c.parse(args); err != nil {
+		return err
+	}
+	start := c.clock.Now().In(c.loc)
+	start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, c.loc)
+	if *month != "" {
+		var err error
+		if start, err = time.ParseInLocation("2006-01", *month, c.loc); err != nil {
+			return fmt.Errorf("-month: cannot parse %q", *month)
+		}
+	}
+	set, err := c.set()
+	if err != nil {
+		return err
+	}
+
+	end := start.AddDate(0, 1, 0)
+	occurrences := set.Between(start, end, true)
+	days := map[int]bool{}
+	for i, t := range occurrences {
+		if t = t.In(c.loc); t.Before(end) {
+			days[t.Day()] = true
+		}
+		occurrences[i] = t
+	}
+	printMonth(c.stdout, start, days)
+	for _, t := range occurrences {
+		if t.Before(end) {
+			fmt.Fprintln(c.stdout, t.Format("Mon Jan _2 15:04:05 MST"))
+		}
+	}
+	return nil
+}
+
+// printMonth prints the grid of the month starting at start, weeks starting on Monday.
+func printMonth(w io.Writer, start time.Time, marked map[int]bool) {
+	title := start.Format("January 2006")
+	fmt.Fprintf(w, "%s%s\n", strings.Repeat(" ", (20-len(title))/2), title)
+	fmt.Fprintln(w, "Mo Tu We Th Fr Sa Su")
+	offset := (int(start.Weekday()) + 6) % 7
+	line := strings.Repeat("   ", offset)
+	last := start.AddDate(0, 1, -1).Day()
+	for day := 1; day <= last; day++ {
+		mark := " "
+		if marked[day] {
+			mark = "*"
+		}
+		line += fmt.Sprintf("%2d%s", day, mark)
+		if (offset+day)%7 == 0 || day == last {
+			fmt.Fprintln(w, strings.TrimRight(line, " "))
+			line = ""
+		}
+	}
+}
@@ -0,0 +1,37 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// convert prints the rule in another form. Text descriptions are output only.
+func convert(c *env, args []string) error {
+	to := c.flags.String("to", "rrule", "output form: rrule, json or text")
+	if err := c.parse(args); err != nil {
+		return err
+	}
+	set, err := c.set()
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(*to) {
+	case "rrule":
+		for _, line := range set.Recurrence() {
+			fmt.Fprintln(c.stdout, line)
+		}
+	case "json":
+		b, err := toJSON(set)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(c.stdout, string(b))
+	case "text":
+		fmt.Fprintln(c.stdout, describe(set))
+	default:
+		return fmt.Errorf("-to: unknown form %q", *to)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// expand prints the occurrences t with after <= t <= before, at most n of them.
+func expand(c *env, args []string) error {
+	var after, before timeFlag
+	c.flags.Var(&after, "after", "first time of the window, inclusive")
+	c.flags.Var(&before, "before", "last time of the window, inclusive")
+	n := c.flags.Int("n", 100, "maximum number of occurrences, 0 for no limit")
+	layout := c.flags.String("layout", time.RFC3339, "Go layout of the printed times")
+	if err := c.parse(args); err != nil {
+		return err
+	}
+	if err := after.resolve("after", c.loc); err != nil {
+		return err
+	}
+	if err := before.resolve("before", c.loc); err != nil {
+		return err
+	}
+	set, err := c.set()
+	if err != nil {
+		return err
+	}
+	if *n <= 0 && before.t.IsZero() && !set.IsFinite() {
+		return errors.New("the rule is infinite, give -before or -n")
+	}
+
+	next := set.Iterator()
+	for i := 0; *n <= 0 || i < *n; {
+		t, ok := next()
+		if !ok || !before.t.IsZero() && t.After(before.t) {
+			break
+		}
+		if t.Before(after.t) {
+			continue
+		}
+		fmt.Fprintln(c.stdout, t.In(c.loc).Format(*layout))
+		i++
+	}
+	return nil
+}
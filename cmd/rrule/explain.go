@@ -0,0 +1,30 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// explain prints whether the rule has an occurrence at the time and, when it
+// has not, the rule part that excludes it.
+func explain(c *env, args []string) error {
+	var at timeFlag
+	c.flags.Var(&at, "at", "time to explain")
+	if err := c.parse(args); err != nil {
+		return err
+	}
+	if err := at.resolve("at", c.loc); err != nil {
+		return err
+	}
+	if at.t.IsZero() {
+		return errors.New("-at is required")
+	}
+	set, err := c.set()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(c.stdout, set.Explain(at.t))
+	return nil
+}
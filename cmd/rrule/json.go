@@ -0,0 +1,188 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// jsonSet is the JSON form of a Set. Times are in RFC 3339 form, or without offset
+// for the times in the location of DTSTART, which is TZID.
+type jsonSet struct {
+	Dtstart string    `json:"dtstart,omitempty"`
+	TZID    string    `json:"tzid,omitempty"`
+	RRule   *jsonRule `json:"rrule,omitempty"`
+	RDate   []string  `json:"rdate,omitempty"`
+	ExDate  []string  `json:"exdate,omitempty"`
+}
+
+type jsonRule struct {
+	Freq       string   `json:"freq"`
+	Interval   int      `json:"interval,omitempty"`
+	Wkst       string   `json:"wkst,omitempty"`
+	Count      int      `json:"count,omitempty"`
+	Until      string   `json:"until,omitempty"`
+	Bysetpos   []int    `json:"bysetpos,omitempty"`
+	Bymonth    []int    `json:"bymonth,omitempty"`
+	Bymonthday []int    `json:"bymonthday,omitempty"`
+	Byyearday  []int    `json:"byyearday,omitempty"`
+	Byweekno   []int    `json:"byweekno,omitempty"`
+	Byday      []string `json:"byday,omitempty"`
+	Byhour     []int    `json:"byhour,omitempty"`
+	Byminute   []int    `json:"byminute,omitempty"`
+	Bysecond   []int    `json:"bysecond,omitempty"`
+	Byeaster   []int    `json:"byeaster,omitempty"`
+}
+
+const jsonLocalLayout = "2006-01-02T15:04:05"
+
+// toJSON returns the JSON form of the set.
+func toJSON(set *rrule.Set) ([]byte, error) {
+	// Locations are compared by name, times in the same zone may not share the *time.Location.
+	loc := set.GetDTStart().Location()
+	format := func(t time.Time) string {
+		if t.Location().String() == loc.String() && loc != time.UTC {
+			return t.Format(jsonLocalLayout)
+		}
+		return t.Format(time.RFC3339)
+	}
+	result := jsonSet{}
+	if dt := set.GetDTStart(); !dt.IsZero() {
+		result.Dtstart = format(dt)
+		if dt.Location() != time.UTC && !rrule.IsFloating(dt) {
+			result.TZID = dt.Location().String()
+		}
+	}
+	if r := set.GetRRule(); r != nil {
+		option := r.OrigOptions
+		rule := &jsonRule{
+			Freq: option.Freq.String(), Interval: option.Interval, Count: option.Count,
+			Bysetpos: option.Bysetpos, Bymonth: option.Bymonth, Bymonthday: option.Bymonthday,
+			Byyearday: option.Byyearday, Byweekno: option.Byweekno, Byhour: option.Byhour,
+			Byminute: option.Byminute, Bysecond: option.Bysecond, Byeaster: option.Byeaster,
+		}
+		if option.Wkst != rrule.MO {
+			rule.Wkst = option.Wkst.String()
+		}
+		if !option.Until.IsZero() {
+			rule.Until = format(option.Until)
+		}
+		for _, wday := range option.Byweekday {
+			rule.Byday = append(rule.Byday, wday.String())
+		}
+		result.RRule = rule
+	}
+	for _, t := range set.GetRDate() {
+		result.RDate = append(result.RDate, format(t))
+	}
+	for _, t := range set.GetExDate() {
+		result.ExDate = append(result.ExDate, format(t))
+	}
+	return json.MarshalIndent(result, "", "  ")
+}
+
+// parseJSON parses the JSON form of a set. Local times are in TZID, or loc without it.
+// The rule is rebuilt as RRULE lines, so that its errors are those of parseLines.
+func parseJSON(s string, loc *time.Location, clock rrule.Clock) (*rrule.Set, error) {
+	var input jsonSet
+	decoder := json.NewDecoder(strings.NewReader(s))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		return nil, fmt.Errorf("json: %v", err)
+	}
+	if input.TZID != "" {
+		var err error
+		if loc, err = time.LoadLocation(input.TZID); err != nil {
+			return nil, fmt.Errorf("json: tzid: %v", err)
+		}
+	}
+	format := func(field, value string) (string, error) {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			if t, err = time.ParseInLocation(jsonLocalLayout, value, loc); err != nil {
+				return "", fmt.Errorf("json: %s: cannot parse time %q", field, value)
+			}
+		}
+		if t.Location() == loc && loc != time.UTC {
+			return t.Format(rrule.LocalDateTimeFormat), nil
+		}
+		return t.UTC().Format(rrule.DateTimeFormat), nil
+	}
+
+	var lines []string
+	param := ""
+	if loc != time.UTC {
+		param = ";TZID=" + loc.String()
+	}
+	if input.Dtstart != "" {
+		dt, err := format("dtstart", input.Dtstart)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, "DTSTART"+param+":"+dt)
+	}
+	if rule := input.RRule; rule != nil {
+		parts := []string{"FREQ=" + rule.Freq}
+		addInt := func(key string, v int) {
+			if v != 0 {
+				parts = append(parts, key+"="+strconv.Itoa(v))
+			}
+		}
+		addInts := func(key string, values []int) {
+			if len(values) != 0 {
+				s := make([]string, len(values))
+				for i, v := range values {
+					s[i] = strconv.Itoa(v)
+				}
+				parts = append(parts, key+"="+strings.Join(s, ","))
+			}
+		}
+		addInt("INTERVAL", rule.Interval)
+		if rule.Wkst != "" {
+			parts = append(parts, "WKST="+rule.Wkst)
+		}
+		addInt("COUNT", rule.Count)
+		if rule.Until != "" {
+			until, err := format("until", rule.Until)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, "UNTIL="+until)
+		}
+		addInts("BYSETPOS", rule.Bysetpos)
+		addInts("BYMONTH", rule.Bymonth)
+		addInts("BYMONTHDAY", rule.Bymonthday)
+		addInts("BYYEARDAY", rule.Byyearday)
+		addInts("BYWEEKNO", rule.Byweekno)
+		if len(rule.Byday) != 0 {
+			parts = append(parts, "BYDAY="+strings.Join(rule.Byday, ","))
+		}
+		addInts("BYHOUR", rule.Byhour)
+		addInts("BYMINUTE", rule.Byminute)
+		addInts("BYSECOND", rule.Bysecond)
+		addInts("BYEASTER", rule.Byeaster)
+		lines = append(lines, "RRULE:"+strings.Join(parts, ";"))
+	}
+	for _, dates := range []struct {
+		name   string
+		values []string
+	}{{"RDATE", input.RDate}, {"EXDATE", input.ExDate}} {
+		for _, value := range dates.values {
+			dt, err := format(strings.ToLower(dates.name), value)
+			if err != nil {
+				return nil, err
+			}
+			lines = append(lines, dates.name+param+":"+dt)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("json: no rule given")
+	}
+	return parseLines(lines, loc, clock)
+}
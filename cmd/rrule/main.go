@@ -0,0 +1,252 @@
+// 2017-2022, Teambition. All rights reserved.
+
+// Command rrule expands, validates, converts and displays recurrence rules.
+//
+// Usage:
+//
+//	rrule expand   [-after T] [-before T] [-n N] [-tz ZONE] [-layout L] [RULE...]
+//	rrule validate [-strict] [-tz ZONE] [RULE...]
+//	rrule convert  [-to rrule|json|text] [-tz ZONE] [RULE...]
+//	rrule cal      [-month YYYY-MM] [-tz ZONE] [RULE...]
+//	rrule explain  -at T [-tz ZONE] [RULE...]
+//
+// RULE is read from the arguments, one line per argument, or from the standard input.
+// It is either iCalendar lines (DTSTART, RRULE, RDATE and EXDATE), a bare RRULE value
+// such as FREQ=DAILY;COUNT=3, or the JSON form printed by convert -to json.
+// A literal \n in an argument separates lines. Times without a zone are in ZONE, UTC by default.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr, clockFunc(time.Now)))
+}
+
+type command struct {
+	name    string
+	summary string
+	run     func(c *env, args []string) error
+}
+
+var commands = []command{
+	{"expand", "print the occurrences over a window", expand},
+	{"validate", "check a rule and report the first error", validate},
+	{"convert", "convert between RRULE, JSON and text", convert},
+	{"cal", "print a month calendar marking the days with occurrences", calendar},
+	{"explain", "tell why a time is or is not an occurrence", explain},
+}
+
+// clockFunc adapts a function such as time.Now to rrule.Clock.
+type clockFunc func() time.Time
+
+func (f clockFunc) Now() time.Time { return f() }
+
+// env holds the streams, the clock and the flags common to all commands.
+type env struct {
+	flags  *flag.FlagSet
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	clock  rrule.Clock
+	tz     string
+	loc    *time.Location
+}
+
+// run runs the command line args and returns the exit status.
+// clock tells the current time, such as the default month of cal.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer, clock rrule.Clock) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stderr)
+		return 2
+	}
+	for _, cmd := range commands {
+		if cmd.name != args[0] {
+			continue
+		}
+		c := &env{flags: flag.NewFlagSet(cmd.name, flag.ContinueOnError), stdin: stdin, stdout: stdout, stderr: stderr, clock: clock}
+		c.flags.SetOutput(stderr)
+		c.flags.StringVar(&c.tz, "tz", "UTC", "time zone of the output and of the times without zone")
+		if err := cmd.run(c, args[1:]); err != nil {
+			if !errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintf(stderr, "rrule %s: %v\n", cmd.name, err)
+			}
+			return 1
+		}
+		return 0
+	}
+	fmt.Fprintf(stderr, "rrule: unknown command %q\n", args[0])
+	usage(stderr)
+	return 2
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: rrule <command> [flags] [RULE...]")
+	fmt.Fprintln(w, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nRun rrule <command> -h for the flags of a command.")
+}
+
+// parse parses the flags and loads the time zone.
+func (c *env) parse(args []string) error {
+	if err := c.flags.Parse(args); err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(c.tz)
+	if err != nil {
+		return fmt.Errorf("-tz: %v", err)
+	}
+	c.loc = loc
+	return nil
+}
+
+// input returns the lines of the rule, from the remaining arguments or the standard input.
+func (c *env) input() ([]string, error) {
+	text := strings.Join(c.flags.Args(), "\n")
+	if c.flags.NArg() == 0 {
+		b, err := io.ReadAll(c.stdin)
+		if err != nil {
+			return nil, err
+		}
+		text = string(b)
+	}
+	text = strings.ReplaceAll(text, `\n`, "\n")
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("no rule given")
+	}
+	return lines, nil
+}
+
+// set parses the input into a Set.
+func (c *env) set() (*rrule.Set, error) {
+	lines, err := c.input()
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(lines[0], "{") {
+		return parseJSON(strings.Join(lines, "\n"), c.loc, c.clock)
+	}
+	return parseLines(lines, c.loc, c.clock)
+}
+
+// lineError is an error in a line of the input.
+type lineError struct {
+	line int
+	name string
+	err  error
+}
+
+func (e *lineError) Error() string {
+	return fmt.Sprintf("line %d (%s): %v", e.line, e.name, e.err)
+}
+
+func (e *lineError) Unwrap() error {
+	return e.err
+}
+
+// parseLines parses iCalendar lines, or a bare RRULE value, into a Set.
+// Errors name the line and the property at fault. A rule without DTSTART starts at the time of clock.
+func parseLines(lines []string, loc *time.Location, clock rrule.Clock) (*rrule.Set, error) {
+	set := &rrule.Set{}
+	for i, line := range lines {
+		name, value := "RRULE", line
+		if j := strings.IndexAny(line, ";:"); j > 0 && !strings.Contains(line[:j], "=") {
+			name, value = strings.ToUpper(line[:j]), line[j+1:]
+		}
+		fail := func(err error) error { return &lineError{line: i + 1, name: name, err: err} }
+		switch name {
+		case "DTSTART":
+			if i != 0 {
+				return nil, fail(errors.New("DTSTART must be the first line"))
+			}
+			dt, err := rrule.StrToDtStart(value, loc)
+			if err != nil {
+				return nil, fail(err)
+			}
+			set.DTStart(dt)
+			loc = dt.Location()
+		case "RRULE":
+			if set.GetRRule() != nil {
+				return nil, fail(errors.New("only one RRULE is supported"))
+			}
+			option, err := rrule.StrToROptionInLocation(value, loc)
+			if err != nil {
+				return nil, fail(err)
+			}
+			r, err := rrule.NewRRule(*option, rrule.WithClock(clock))
+			if err != nil {
+				return nil, fail(err)
+			}
+			set.RRule(r)
+		case "RDATE", "EXDATE":
+			dates, err := rrule.StrToDatesInLoc(value, loc)
+			if err != nil {
+				return nil, fail(err)
+			}
+			for _, dt := range dates {
+				if name == "RDATE" {
+					set.RDate(dt)
+				} else {
+					set.ExDate(dt)
+				}
+			}
+		default:
+			return nil, fail(errors.New("unsupported property"))
+		}
+	}
+	return set, nil
+}
+
+// parseTime parses a time given as a flag, in RFC 3339, iCalendar or date-only form.
+// Times without zone are in loc.
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, rrule.DateTimeFormat} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02", rrule.LocalDateTimeFormat, rrule.DateFormat} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cannot parse time %q", s)
+}
+
+// timeFlag is a flag holding a time, parsed once the time zone is known.
+type timeFlag struct {
+	value string
+	t     time.Time
+}
+
+func (f *timeFlag) String() string     { return f.value }
+func (f *timeFlag) Set(s string) error { f.value = s; return nil }
+
+func (f *timeFlag) resolve(name string, loc *time.Location) error {
+	if f.value == "" {
+		return nil
+	}
+	t, err := parseTime(f.value, loc)
+	if err != nil {
+		return fmt.Errorf("-%s: %v", name, err)
+	}
+	f.t = t
+	return nil
+}
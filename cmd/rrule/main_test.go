@@ -0,0 +1,184 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// now is the current time of the commands run by the tests.
+var now = time.Date(1997, 9, 15, 12, 0, 0, 0, time.UTC)
+
+func runCommand(args []string, stdin string) (stdout, stderr string, status int) {
+	var out, err bytes.Buffer
+	status = run(args, strings.NewReader(stdin), &out, &err, clockFunc(func() time.Time { return now }))
+	return out.String(), err.String(), status
+}
+
+func TestExpand(t *testing.T) {
+	for _, c := range []struct {
+		args  []string
+		stdin string
+		want  string
+	}{
+		{[]string{"expand", `DTSTART:19970902T090000Z\nRRULE:FREQ=DAILY;COUNT=3`}, "",
+			"1997-09-02T09:00:00Z\n1997-09-03T09:00:00Z\n1997-09-04T09:00:00Z\n"},
+		// A Set from the standard input, in another zone, over a window.
+		{[]string{"expand", "-tz", "Asia/Shanghai", "-after", "1997-09-03", "-before", "1997-09-06T09:00:00Z"},
+			"DTSTART:19970902T090000Z\nRRULE:FREQ=DAILY;COUNT=10\nEXDATE:19970904T090000Z\nRDATE:19970905T120000Z\n",
+			"1997-09-03T17:00:00+08:00\n1997-09-05T17:00:00+08:00\n1997-09-05T20:00:00+08:00\n1997-09-06T17:00:00+08:00\n"},
+		{[]string{"expand", "-n", "2", "-layout", "2006-01-02", "DTSTART:19970902T090000Z", "RRULE:FREQ=WEEKLY"}, "",
+			"1997-09-02\n1997-09-09\n"},
+	} {
+		stdout, stderr, status := runCommand(c.args, c.stdin)
+		if status != 0 || stdout != c.want {
+			t.Errorf("%v: get %q %q %d, want %q", c.args, stdout, stderr, status, c.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		want  string
+	}{
+		{`DTSTART:19970902T090000Z\nRRULE:FREQ=DAILY;COUNT=3`, ""},
+		{`DTSTART:19970902T090000Z\nRRULE:FREQ=DAILY;BYHOUR=24`, "line 2 (RRULE): byhour must be between 0 and 23"},
+		{`FREQ=DAILY;BYDAY=XX`, "line 1 (RRULE): undefined weekday: XX"},
+		{`RRULE:FREQ=DAILY\nDTSTART:19970902T090000Z`, "line 2 (DTSTART): DTSTART must be the first line"},
+		{`DTSTART:19970902T090000Z\nEXRULE:FREQ=DAILY`, "line 2 (EXRULE): unsupported property"},
+		{`{"rrule": {"freq": "DAILY", "bymonth": [13]}}`, "line 1 (RRULE): bymonth must be between 1 and 12"},
+	} {
+		stdout, stderr, status := runCommand([]string{"validate", c.input}, "")
+		if c.want == "" && (status != 0 || stdout != "ok\n") ||
+			c.want != "" && (status != 1 || !strings.Contains(stderr, c.want)) {
+			t.Errorf("%s: get %q %q %d, want %q", c.input, stdout, stderr, status, c.want)
+		}
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	input := `DTSTART:19970902T090000Z\nRRULE:FREQ=WEEKLY;COUNT=3;BYMONTHDAY=2`
+	want := "error: BYMONTHDAY must not be used with FREQ=WEEKLY (RFC 5545, section 3.3.10)\n"
+	if stdout, stderr, status := runCommand([]string{"validate", input}, ""); status != 0 || stdout != "" || stderr != want {
+		t.Errorf("get %q %q %d, want %q", stdout, stderr, status, want)
+	}
+	// Warnings do not prevent ok.
+	warning := `DTSTART:19970902T090000Z\nRRULE:FREQ=WEEKLY;COUNT=3;BYDAY=MO`
+	if stdout, stderr, status := runCommand([]string{"validate", "-strict", warning}, ""); status != 0 || stdout != "ok\n" || !strings.HasPrefix(stderr, "warning: DTSTART") {
+		t.Errorf("get %q %q %d", stdout, stderr, status)
+	}
+	if _, stderr, status := runCommand([]string{"validate", "-strict", input}, ""); status != 1 || !strings.Contains(stderr, "RFC 5545") {
+		t.Errorf("get %q %d", stderr, status)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	input := "DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=MONTHLY;INTERVAL=2;COUNT=10;BYDAY=+1SU,-1SU\nEXDATE;TZID=America/New_York:19971005T090000\n"
+	stdout, stderr, status := runCommand([]string{"convert", "-to", "json"}, input)
+	if status != 0 {
+		t.Fatal(stderr)
+	}
+	// JSON converts back to the same rule.
+	value, stderr, status := runCommand([]string{"convert", "-to", "rrule"}, stdout)
+	if status != 0 || value != input {
+		t.Errorf("get %q %q, want %q", value, stderr, input)
+	}
+
+	value, _, _ = runCommand([]string{"convert", "-to", "text"}, input)
+	want := "every 2 months on the 1st Sunday and the last Sunday, 10 times, starting Sep 2, 1997 09:00 America/New_York, except 1 date\n"
+	if value != want {
+		t.Errorf("get %q, want %q", value, want)
+	}
+}
+
+func TestConvertText(t *testing.T) {
+	for _, c := range []struct {
+		input string
+		want  string
+	}{
+		{"DTSTART:19970902T090000Z\nRRULE:FREQ=MINUTELY;BYSECOND=15,45", "every minute at seconds 15 and 45 past the minute"},
+		{"DTSTART:19970902T090000Z\nRRULE:FREQ=HOURLY;BYMINUTE=0,30", "every hour at minutes 0 and 30 past the hour"},
+	} {
+		value, stderr, _ := runCommand([]string{"convert", "-to", "text", c.input}, "")
+		if !strings.HasPrefix(value, c.want+",") {
+			t.Errorf("%s: get %q %q, want %q", c.input, value, stderr, c.want)
+		}
+	}
+}
+
+func TestConvertJSONMixedZones(t *testing.T) {
+	input := "DTSTART;TZID=America/New_York:20240101T090000\nRRULE:FREQ=DAILY;COUNT=12\n" +
+		"RDATE;TZID=Europe/London:20240110T120000\nRDATE:20240111T120000Z\nEXDATE;TZID=Asia/Tokyo:20240104T230000\n"
+	b, stderr, status := runCommand([]string{"convert", "-to", "json"}, input)
+	if status != 0 {
+		t.Fatal(stderr)
+	}
+	// Times in another zone than TZID keep their offset.
+	if !strings.Contains(b, `"2024-01-10T12:00:00Z"`) || !strings.Contains(b, `"2024-01-04T23:00:00+09:00"`) {
+		t.Errorf("get %s", b)
+	}
+	want, _, _ := runCommand([]string{"expand"}, input)
+	if value, stderr, _ := runCommand([]string{"expand"}, b); value != want {
+		t.Errorf("get %q %q, want %q", value, stderr, want)
+	}
+}
+
+func TestCalendar(t *testing.T) {
+	stdout, stderr, status := runCommand([]string{"cal", "-month", "1997-09", `DTSTART:19970902T090000Z\nRRULE:FREQ=WEEKLY;COUNT=3;BYDAY=TU,FR`}, "")
+	want := `   September 1997
+Mo Tu We Th Fr Sa Su
+ 1  2* 3  4  5* 6  7
+ 8  9*10 11 12 13 14
+15 16 17 18 19 20 21
+22 23 24 25 26 27 28
+29 30
+Tue Sep  2 09:00:00 UTC
+Fri Sep  5 09:00:00 UTC
+Tue Sep  9 09:00:00 UTC
+`
+	if status != 0 || stdout != want {
+		t.Errorf("get %q %q, want %q", stdout, stderr, want)
+	}
+}
+
+func TestExplain(t *testing.T) {
+	stdout, stderr, status := runCommand([]string{"explain", "-at", "2024-02-29T09:00:00Z", `DTSTART:20240131T090000Z\nRRULE:FREQ=MONTHLY`}, "")
+	want := "day 29 of February is not in BYMONTHDAY=31, inferred from DTSTART; February 2024 has 29 days\n"
+	if status != 0 || stdout != want {
+		t.Errorf("get %q %q, want %q", stdout, stderr, want)
+	}
+	if _, stderr, status := runCommand([]string{"explain", "FREQ=DAILY"}, ""); status != 1 || !strings.Contains(stderr, "-at") {
+		t.Errorf("get %q %d", stderr, status)
+	}
+}
+
+func TestCalendarCurrentMonth(t *testing.T) {
+	// The current month in the time zone, and a rule without DTSTART starting now.
+	stdout, stderr, status := runCommand([]string{"cal", "-tz", "Asia/Tokyo", "FREQ=WEEKLY;COUNT=2"}, "")
+	want := `   September 1997
+Mo Tu We Th Fr Sa Su
+ 1  2  3  4  5  6  7
+ 8  9 10 11 12 13 14
+15*16 17 18 19 20 21
+22*23 24 25 26 27 28
+29 30
+Mon Sep 15 21:00:00 JST
+Mon Sep 22 21:00:00 JST
+`
+	if status != 0 || stdout != want {
+		t.Errorf("get %q %q, want %q", stdout, stderr, want)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	if _, stderr, status := runCommand([]string{"unknown"}, ""); status != 2 || !strings.Contains(stderr, "usage") {
+		t.Errorf("get %q %d", stderr, status)
+	}
+	if _, stderr, status := runCommand([]string{"expand", "-tz", "Nowhere/City", "FREQ=DAILY"}, ""); status != 1 || !strings.Contains(stderr, "-tz") {
+		t.Errorf("get %q %d", stderr, status)
+	}
+}
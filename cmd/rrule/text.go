@@ -0,0 +1,183 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+var weekdayNames = [...]string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+var freqUnits = map[rrule.Frequency]string{
+	rrule.YEARLY: "year", rrule.MONTHLY: "month", rrule.WEEKLY: "week", rrule.DAILY: "day",
+	rrule.HOURLY: "hour", rrule.MINUTELY: "minute", rrule.SECONDLY: "second",
+}
+
+// describe returns an English description of the set, such as
+// "every 2 weeks on Monday and Friday at 09:00, 10 times, starting Sep 2, 1997".
+func describe(set *rrule.Set) string {
+	var parts []string
+	if r := set.GetRRule(); r != nil {
+		parts = append(parts, describeRule(r.OrigOptions))
+		parts = append(parts, "starting "+formatTime(r.GetDTStart()))
+	}
+	if n := len(set.GetRDate()); n != 0 {
+		parts = append(parts, "plus "+plural(n, "date"))
+	}
+	if n := len(set.GetExDate()); n != 0 {
+		parts = append(parts, "except "+plural(n, "date"))
+	}
+	if len(parts) == 0 {
+		return "no occurrences"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// describeRule describes the RRULE parts of option.
+func describeRule(option rrule.ROption) string {
+	unit := freqUnits[option.Freq]
+	s := "every " + unit
+	if option.Interval > 1 {
+		s = "every " + plural(option.Interval, unit)
+	}
+
+	if len(option.Byweekno) != 0 {
+		s += " in week " + joinWords(ints(option.Byweekno, strconv.Itoa))
+	}
+	if len(option.Byyearday) != 0 {
+		s += " on the " + joinWords(ints(option.Byyearday, ordinal)) + " day of the year"
+	}
+	if len(option.Bymonthday) != 0 {
+		s += " on the " + joinWords(ints(option.Bymonthday, ordinal)) + " day of the month"
+	}
+	if len(option.Byweekday) != 0 {
+		days := make([]string, len(option.Byweekday))
+		for i, wday := range option.Byweekday {
+			days[i] = weekdayNames[wday.Day()]
+			if n := wday.N(); n != 0 {
+				days[i] = "the " + ordinal(n) + " " + days[i]
+			}
+		}
+		s += " on " + joinWords(days)
+	}
+	if len(option.Byeaster) != 0 {
+		s += " on Easter " + joinWords(ints(option.Byeaster, func(n int) string {
+			return fmt.Sprintf("%+d days", n)
+		}))
+	}
+	if len(option.Bymonth) != 0 {
+		s += " in " + joinWords(ints(option.Bymonth, func(n int) string { return time.Month(n).String() }))
+	}
+	if clock := describeClock(option); clock != "" {
+		s += " at " + clock
+	}
+	if len(option.Bysetpos) != 0 {
+		s += ", only the " + joinWords(ints(option.Bysetpos, ordinal)) + " of each " + unit
+	}
+	if option.Count != 0 {
+		s += ", " + plural(option.Count, "time")
+	}
+	if !option.Until.IsZero() {
+		s += ", until " + formatTime(option.Until)
+	}
+	return s
+}
+
+// describeClock describes BYHOUR, BYMINUTE and BYSECOND as times of day,
+// or an empty string if there are none or too many of them.
+func describeClock(option rrule.ROption) string {
+	if len(option.Byhour) == 0 && len(option.Byminute) == 0 && len(option.Bysecond) == 0 {
+		return ""
+	}
+	hours, minutes, seconds := option.Byhour, option.Byminute, option.Bysecond
+	if len(hours) == 0 && len(minutes) == 0 {
+		return "seconds " + joinWords(ints(seconds, strconv.Itoa)) + " past the minute"
+	}
+	if len(hours) == 0 {
+		return "minutes " + joinWords(ints(minutes, strconv.Itoa)) + " past the hour"
+	}
+	if len(minutes) == 0 {
+		minutes = []int{0}
+		if !option.Dtstart.IsZero() {
+			minutes = []int{option.Dtstart.Minute()}
+		}
+	}
+	if len(hours)*len(minutes)*len(seconds) > 12 {
+		return "hours " + joinWords(ints(hours, strconv.Itoa))
+	}
+	var times []string
+	for _, h := range hours {
+		for _, m := range minutes {
+			if len(seconds) == 0 {
+				times = append(times, fmt.Sprintf("%02d:%02d", h, m))
+			}
+			for _, sec := range seconds {
+				times = append(times, fmt.Sprintf("%02d:%02d:%02d", h, m, sec))
+			}
+		}
+	}
+	return joinWords(times)
+}
+
+func formatTime(t time.Time) string {
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format("Jan 2, 2006")
+	}
+	if t.Location() == time.UTC {
+		return t.Format("Jan 2, 2006 15:04 UTC")
+	}
+	if rrule.IsFloating(t) {
+		return t.Format("Jan 2, 2006 15:04")
+	}
+	return t.Format("Jan 2, 2006 15:04 ") + t.Location().String()
+}
+
+func plural(n int, unit string) string {
+	if n == 1 {
+		return "1 " + unit
+	}
+	return strconv.Itoa(n) + " " + unit + "s"
+}
+
+// ordinal returns "1st", "2nd", ..., or "last", "2nd to last", ... for negative n.
+func ordinal(n int) string {
+	if n == -1 {
+		return "last"
+	}
+	if n < 0 {
+		return ordinal(-n) + " to last"
+	}
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+func ints(values []int, format func(int) string) []string {
+	result := make([]string, len(values))
+	for i, v := range values {
+		result[i] = format(v)
+	}
+	return result
+}
+
+// joinWords joins words as "a, b and c".
+func joinWords(words []string) string {
+	if len(words) <= 1 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
@@ -0,0 +1,36 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/teambition/rrule-go"
+)
+
+// validate parses the rule and reports the first error, naming the line and property at fault.
+// It then prints the issues found by Lint on the standard error, and ok if none of them
+// is an error. The errors only fail the command with -strict.
+func validate(c *env, args []string) error {
+	strict := c.flags.Bool("strict", false, "fail on the RFC 5545 violations found by lint")
+	if err := c.parse(args); err != nil {
+		return err
+	}
+	set, err := c.set()
+	if err != nil {
+		return err
+	}
+	failed := false
+	for _, issue := range set.Lint() {
+		fmt.Fprintln(c.stderr, issue)
+		failed = failed || issue.Severity == rrule.Error
+	}
+	if *strict && failed {
+		return errors.New("the rule violates RFC 5545")
+	}
+	if !failed {
+		fmt.Fprintln(c.stdout, "ok")
+	}
+	return nil
+}
@@ -0,0 +1,260 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"time"
+)
+
+// Conflict is a pair of overlapping instances of two series.
+type Conflict struct {
+	A Instance
+	B Instance
+}
+
+// FirstConflict returns the first pair of overlapping instances of a and b,
+// and false if they never overlap. Instances last the duration of their set or
+// of their override, or duration for sets without one. Instances overlap when
+// they share some time, or start at the same time if they have no duration.
+//
+// When both sets are made of a single rule without COUNT, UNTIL or BYxxx parts,
+// with a frequency of fixed length and a location without daylight saving time,
+// the first conflict is solved arithmetically, and is the one the sweep would find.
+// Otherwise the instances of both sets are swept in order until they overlap
+// or end, which, for rules without COUNT or UNTIL, is the default UNTIL.
+func FirstConflict(a, b *Set, duration time.Duration) (Conflict, bool) {
+	a, b = withDefaultDuration(a, duration), withDefaultDuration(b, duration)
+	if pa, ok := a.period(); ok {
+		if pb, ok := b.period(); ok {
+			return firstPeriodicConflict(a, b, pa, pb)
+		}
+	}
+	var result Conflict
+	found := false
+	sweepConflicts(a.instanceIterator(), b.instanceIterator(), func(c Conflict) bool {
+		result, found = c, true
+		return false
+	})
+	return result, found
+}
+
+// Conflicts returns the pairs of overlapping instances of a and b that overlap [after, before),
+// following the semantics of Instances. See FirstConflict.
+func Conflicts(a, b *Set, after, before time.Time, duration time.Duration) []Conflict {
+	a, b = withDefaultDuration(a, duration), withDefaultDuration(b, duration)
+	result := []Conflict{}
+	sweepConflicts(sliceInstances(a.Instances(after, before)), sliceInstances(b.Instances(after, before)), func(c Conflict) bool {
+		result = append(result, c)
+		return true
+	})
+	return result
+}
+
+// withDefaultDuration returns set, or a copy of it with duration if it has none.
+func withDefaultDuration(set *Set, duration time.Duration) *Set {
+	if set.duration != 0 || duration <= 0 {
+		return set
+	}
+	set = set.clone()
+	set.duration = duration
+	return set
+}
+
+// overlapsWith reports whether the instances share some time,
+// or start at the same time.
+func (i Instance) overlapsWith(j Instance) bool {
+	return i.Start.Before(j.End) && j.Start.Before(i.End) || i.Start.Equal(j.Start)
+}
+
+// sweepConflicts walks the instances of a and b by start, and calls found with each
+// pair of overlapping instances until it returns false.
+func sweepConflicts(a, b func() (Instance, bool), found func(Conflict) bool) {
+	var active [2][]Instance
+	next := [2]func() (Instance, bool){a, b}
+	var heads [2]Instance
+	var ok [2]bool
+	heads[0], ok[0] = a()
+	heads[1], ok[1] = b()
+	for ok[0] || ok[1] {
+		side := 0
+		if !ok[0] || ok[1] && heads[1].Start.Before(heads[0].Start) {
+			side = 1
+		}
+		x, other := heads[side], 1-side
+		heads[side], ok[side] = next[side]()
+
+		// Instances of the other side that ended before x started cannot overlap x or later instances.
+		kept := active[other][:0]
+		for _, y := range active[other] {
+			if y.End.After(x.Start) || y.Start.Equal(x.Start) {
+				kept = append(kept, y)
+			}
+		}
+		active[other] = kept
+		for _, y := range active[other] {
+			if !x.overlapsWith(y) {
+				continue
+			}
+			c := Conflict{A: x, B: y}
+			if side == 1 {
+				c = Conflict{A: y, B: x}
+			}
+			if !found(c) {
+				return
+			}
+		}
+		active[side] = append(active[side], x)
+	}
+}
+
+// instanceIterator returns an iterator over the instances of the set.
+func (set *Set) instanceIterator() func() (Instance, bool) {
+	occurrences, _ := set.occurrences(context.Background())
+	return func() (Instance, bool) {
+		o, ok := occurrences()
+		if !ok {
+			return Instance{}, false
+		}
+		return newInstance(o, set.duration), true
+	}
+}
+
+// sliceInstances returns an iterator over instances.
+func sliceInstances(instances []Instance) func() (Instance, bool) {
+	return func() (Instance, bool) {
+		if len(instances) == 0 {
+			return Instance{}, false
+		}
+		i := instances[0]
+		instances = instances[1:]
+		return i, true
+	}
+}
+
+// period returns the period in seconds between the occurrences of the set,
+// if they are exactly the multiples of it from DTSTART.
+func (set *Set) period() (int64, bool) {
+	if !set.onlyRRule() {
+		return 0, false
+	}
+	r := set.rrule
+	o := r.OrigOptions
+	if r.count != 0 || !o.Until.IsZero() || len(o.Bysetpos) != 0 || len(o.Bymonth) != 0 ||
+		len(o.Bymonthday) != 0 || len(o.Byyearday) != 0 || len(o.Byweekno) != 0 || len(o.Byweekday) != 0 ||
+		len(o.Byhour) != 0 || len(o.Byminute) != 0 || len(o.Bysecond) != 0 || len(o.Byeaster) != 0 {
+		return 0, false
+	}
+	if loc := r.dtstart.Location(); loc != time.UTC && loc != Floating {
+		return 0, false
+	}
+	unit := map[Frequency]int64{WEEKLY: 7 * 86400, DAILY: 86400, HOURLY: 3600, MINUTELY: 60, SECONDLY: 1}[r.freq]
+	return unit * int64(r.interval), unit != 0
+}
+
+// firstPeriodicConflict solves the first conflict of two periodic sets. Their occurrences
+// are a0 + i*pa and b0 + j*pb for i, j >= 0, in seconds. The first conflict is found by the
+// later start of the pair: the first start of a whose latest preceding start of b is less than
+// the duration of b before it, or the reverse. Each is the first solution of a linear
+// congruence in a range, solved in logarithmic time. The pair is then chosen the way
+// sweepConflicts does, so that both give the same conflict.
+func firstPeriodicConflict(a, b *Set, pa, pb int64) (Conflict, bool) {
+	a0, b0 := a.rrule.dtstart.Unix(), b.rrule.dtstart.Unix()
+	// Starts are whole seconds, they are less than a duration apart when they are less than its ceiling.
+	da, db := ceilDiv(int64(a.duration), int64(time.Second)), ceilDiv(int64(b.duration), int64(time.Second))
+
+	t, ok := int64(0), false
+	if i, found := firstNear(a0, pa, b0, pb, db); found {
+		t, ok = a0+i*pa, true
+	}
+	if j, found := firstNear(b0, pb, a0, pa, da); found && (!ok || b0+j*pb < t) {
+		t, ok = b0+j*pb, true
+	}
+	if !ok {
+		return Conflict{}, false
+	}
+
+	// At t, sweepConflicts compares the instance of a starting at t with the instances
+	// of b that started before, then the instance of b starting at t with those of a.
+	var sa, sb int64
+	hasA := t >= a0 && (t-a0)%pa == 0
+	if sb = b0 + maxInt64(0, floorDiv(t-db-b0, pb)+1)*pb; hasA && sb < t {
+		sa = t
+	} else {
+		sb = t
+		if sa = a0 + maxInt64(0, floorDiv(t-da-a0, pa)+1)*pa; sa > t && hasA {
+			sa = t
+		}
+	}
+	if sa > a.rrule.until.Unix() || sb > b.rrule.until.Unix() {
+		return Conflict{}, false
+	}
+	return Conflict{A: periodicInstance(a, sa), B: periodicInstance(b, sb)}, true
+}
+
+// periodicInstance returns the instance of the periodic set starting at the Unix time sec.
+func periodicInstance(set *Set, sec int64) Instance {
+	start := time.Unix(sec, 0).In(set.rrule.dtstart.Location())
+	return Instance{Start: start, End: start.Add(set.duration), RecurrenceID: start}
+}
+
+// firstNear returns the smallest i >= 0 such that s + i*p is not before o, and the latest
+// o + j*q not after it is less than d before it, or exactly it if d is 0.
+func firstNear(s, p, o, q, d int64) (int64, bool) {
+	i0 := maxInt64(0, ceilDiv(o-s, p))
+	c, k := pymod64(s+i0*p-o, q), maxInt64(d, 1)
+	if c < k {
+		return i0, true
+	}
+	// (c + i*p) mod q < k, that is i*p mod q in [q-c, q-c+k-1].
+	i := firstInRange(p%q, q, q-c, q-c+k-1)
+	return i0 + i, i >= 0
+}
+
+// firstInRange returns the smallest x >= 0 with l <= a*x mod m <= r, or -1 if there is none,
+// for 0 <= a < m and 0 <= l <= r < m. Each step reduces (a, m) to (m mod a, a), like Euclid.
+func firstInRange(a, m, l, r int64) int64 {
+	if l == 0 {
+		return 0
+	}
+	if a == 0 {
+		return -1
+	}
+	if k := ceilDiv(l, a); a*k <= r {
+		return k
+	}
+	// [l, r] lies strictly between two multiples of a. a*x = l' + m*y for some l' in [l, r],
+	// so m*y mod a must be in [-r mod a, -l mod a].
+	y := firstInRange(m%a, a, a-r%a, a-l%a)
+	if y < 0 {
+		return -1
+	}
+	return ceilDiv(l+m*y, a)
+}
+
+func pymod64(a, b int64) int64 {
+	r := a % b
+	if r < 0 {
+		r += b
+	}
+	return r
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func ceilDiv(a, b int64) int64 {
+	return -floorDiv(-a, b)
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
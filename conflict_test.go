@@ -0,0 +1,178 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func ruleSet(t *testing.T, option ROption) *Set {
+	r, err := NewRRule(option)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set := &Set{}
+	set.RRule(r)
+	return set
+}
+
+func TestFirstConflictPeriodic(t *testing.T) {
+	for _, c := range []struct {
+		a, b     ROption
+		duration time.Duration
+		want     bool
+	}{
+		// Every other day at 9:00 and every 3 days at 9:30 meet on the 5th.
+		{ROption{Freq: DAILY, Interval: 2, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+			ROption{Freq: DAILY, Interval: 3, Dtstart: time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)},
+			time.Hour, true},
+		// Alternate days never meet.
+		{ROption{Freq: DAILY, Interval: 2, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+			ROption{Freq: DAILY, Interval: 2, Dtstart: time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)},
+			time.Hour, false},
+		// Every 5 hours meets the weekly instance once the hours line up.
+		{ROption{Freq: WEEKLY, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+			ROption{Freq: HOURLY, Interval: 5, Dtstart: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+			time.Hour, true},
+		// Instances without duration conflict when they start together.
+		{ROption{Freq: MINUTELY, Interval: 7, Dtstart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			ROption{Freq: MINUTELY, Interval: 11, Dtstart: time.Date(2024, 1, 1, 0, 3, 0, 0, time.UTC)},
+			0, true},
+		// B starts long after A.
+		{ROption{Freq: HOURLY, Interval: 6, Dtstart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			ROption{Freq: DAILY, Interval: 4, Dtstart: time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)},
+			2 * time.Hour, true},
+		// Several instances of A overlap the first instance of B, the sweep keeps the earliest.
+		{ROption{Freq: MINUTELY, Interval: 5, Dtstart: time.Date(2024, 1, 1, 12, 43, 0, 0, time.UTC)},
+			ROption{Freq: WEEKLY, Dtstart: time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)},
+			30 * time.Minute, true},
+		// Co-prime intervals of about 1000 days, meeting hundreds of years later.
+		{ROption{Freq: DAILY, Interval: 997, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+			ROption{Freq: DAILY, Interval: 1009, Dtstart: time.Date(2025, 5, 15, 9, 0, 0, 0, time.UTC)},
+			time.Hour, false},
+		{ROption{Freq: DAILY, Interval: 97, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)},
+			ROption{Freq: DAILY, Interval: 101, Dtstart: time.Date(2025, 5, 15, 9, 30, 0, 0, time.UTC)},
+			time.Hour, true},
+		// Durations much longer than the periods.
+		{ROption{Freq: SECONDLY, Interval: 7, Dtstart: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+			ROption{Freq: MINUTELY, Interval: 13, Dtstart: time.Date(2024, 1, 1, 0, 0, 3, 0, time.UTC)},
+			1500 * time.Millisecond, true},
+	} {
+		a, b := ruleSet(t, c.a), ruleSet(t, c.b)
+		value, ok := FirstConflict(a, b, c.duration)
+		if ok && !(a.Contains(value.A.Start) && b.Contains(value.B.Start) && value.A.overlapsWith(value.B)) {
+			t.Errorf("%v and %v: %v is not a conflict", c.a, c.b, value)
+		}
+		if ok != c.want {
+			t.Errorf("%v and %v: get %v, want %v", c.a, c.b, ok, c.want)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		// The arithmetic agrees with sweeping the instances.
+		var want Conflict
+		a, b = withDefaultDuration(a, c.duration), withDefaultDuration(b, c.duration)
+		sweepConflicts(a.instanceIterator(), b.instanceIterator(), func(c Conflict) bool {
+			want = c
+			return false
+		})
+		if value != want {
+			t.Errorf("%v and %v: get %v, want %v", c.a, c.b, value, want)
+		}
+	}
+}
+
+func TestFirstConflictAgreesWithSweep(t *testing.T) {
+	dtstart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	frequencies := []Frequency{WEEKLY, DAILY, HOURLY, MINUTELY}
+	for n := 0; n < 300; n++ {
+		// A deterministic mix of frequencies, intervals, offsets and durations.
+		option := func(k int) ROption {
+			return ROption{Freq: frequencies[(n+k)%4], Interval: 1 + (n*7+k*3)%11,
+				Dtstart: dtstart.Add(time.Duration((n*37+k*53)%600) * time.Minute)}
+		}
+		a, b := ruleSet(t, option(0)), ruleSet(t, option(1))
+		a.Duration(time.Duration(n*13%90) * time.Minute)
+		b.Duration(time.Duration(n*29%45) * time.Minute)
+		value, ok := FirstConflict(a, b, 0)
+
+		var want Conflict
+		found := false
+		a.rrule.until, b.rrule.until = dtstart.AddDate(1, 0, 0), dtstart.AddDate(1, 0, 0)
+		sweepConflicts(a.instanceIterator(), b.instanceIterator(), func(c Conflict) bool {
+			want, found = c, true
+			return false
+		})
+		if found && (!ok || value != want) || !found && ok && value.A.Start.Before(dtstart.AddDate(1, 0, 0)) {
+			t.Errorf("%v and %v: get %v %v, want %v %v", a.rrule.OrigOptions, b.rrule.OrigOptions, value, ok, want, found)
+		}
+	}
+}
+
+func TestFirstInRange(t *testing.T) {
+	for m := int64(1); m < 40; m++ {
+		for a := int64(0); a < m; a++ {
+			for l := int64(0); l < m; l++ {
+				for r := l; r < m; r++ {
+					want := int64(-1)
+					for x := int64(0); x < m; x++ {
+						if v := a * x % m; l <= v && v <= r {
+							want = x
+							break
+						}
+					}
+					if value := firstInRange(a, m, l, r); value != want {
+						t.Fatalf("%d*x mod %d in [%d, %d]: get %d, want %d", a, m, l, r, value, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestFirstConflictSweep(t *testing.T) {
+	a := ruleSet(t, ROption{Freq: MONTHLY, Bymonthday: []int{-1}, Dtstart: time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC)})
+	b := ruleSet(t, ROption{Freq: WEEKLY, Byweekday: []Weekday{FR}, Dtstart: time.Date(2024, 1, 5, 9, 30, 0, 0, time.UTC)})
+	b.Duration(15 * time.Minute)
+	// The last day of the month is a Friday in May 2024, but that one is excluded.
+	b.ExDate(time.Date(2024, 5, 31, 9, 30, 0, 0, time.UTC))
+	value, ok := FirstConflict(a, b, time.Hour)
+	want := Conflict{
+		A: instance(time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC)),
+		B: instance(time.Date(2025, 1, 31, 9, 30, 0, 0, time.UTC), time.Date(2025, 1, 31, 9, 45, 0, 0, time.UTC)),
+	}
+	if !ok || value != want {
+		t.Errorf("get %v %v, want %v", value, ok, want)
+	}
+
+	// Back-to-back instances do not overlap.
+	c := ruleSet(t, ROption{Freq: DAILY, Count: 3, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)})
+	d := ruleSet(t, ROption{Freq: DAILY, Count: 3, Dtstart: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)})
+	if value, ok := FirstConflict(c, d, time.Hour); ok {
+		t.Errorf("get %v, want no conflict", value)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	a := ruleSet(t, ROption{Freq: DAILY, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)})
+	a.Duration(2 * time.Hour)
+	b := ruleSet(t, ROption{Freq: DAILY, Interval: 2, Dtstart: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)})
+	b.Override(Override{RecurrenceID: time.Date(2024, 1, 3, 10, 0, 0, 0, time.UTC), Start: time.Date(2024, 1, 3, 14, 0, 0, 0, time.UTC)})
+	value := Conflicts(a, b, time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC), time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC), 30*time.Minute)
+	want := []Conflict{
+		{A: instance(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC)),
+			B: instance(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC))},
+		{A: instance(time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC), time.Date(2024, 1, 5, 11, 0, 0, 0, time.UTC)),
+			B: instance(time.Date(2024, 1, 5, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 5, 10, 30, 0, 0, time.UTC))},
+	}
+	if len(value) != len(want) {
+		t.Fatalf("get %v, want %v", value, want)
+	}
+	for i := range value {
+		if value[i] != want[i] {
+			t.Errorf("get %v, want %v", value[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,156 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"time"
+)
+
+// Contains reports whether t is an occurrence of the RRule.
+// Only the period containing t is evaluated against the BYxxx filters and BYSETPOS,
+// so the cost does not depend on the distance between DTSTART and t,
+// except for rules with COUNT, whose earlier occurrences are counted period by period.
+func (r *RRule) Contains(t time.Time) bool {
+	if !r.selects(t) {
+		return false
+	}
+	return r.count == 0 || r.iterator(context.Background()).countBetween(time.Time{}, t, false) < r.count
+}
+
+// Contains reports whether t is an occurrence of the rrule.Set,
+// that is an RDATE or an occurrence of the RRULE, and not an EXDATE.
+// Overridden occurrences are only found at their effective time.
+func (set *Set) Contains(t time.Time) bool {
+	applied := set.appliedOverrides()
+	for _, o := range applied {
+		if o.effectiveStart().Equal(t) {
+			return true
+		}
+	}
+	return overrideOf(applied, t) == nil && set.baseContains(t)
+}
+
+// selects reports whether t is between DTSTART and UNTIL and selected
+// by the period containing it, regardless of COUNT.
+func (r *RRule) selects(t time.Time) bool {
+	t = t.In(r.dtstart.Location())
+	if t.Nanosecond() != 0 || t.Before(r.dtstart) || t.After(r.until) {
+		return false
+	}
+	iterator := r.iteratorAt(t)
+	return iterator != nil && iterator.periodContains(t)
+}
+
+// iteratorAt returns an iterator positioned at the period containing t,
+// given in the location of DTSTART, or nil if the rule never visits that period
+// because of INTERVAL or, for HOURLY, MINUTELY and SECONDLY rules, of BYHOUR,
+// BYMINUTE and BYSECOND. Its total and count are not restored.
+func (r *RRule) iteratorAt(t time.Time) *rIterator {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	weekday := toPyWeekday(t.Weekday())
+	dyear, dmonth, dday := r.dtstart.Date()
+	dhour, dminute, dsecond := r.dtstart.Clock()
+	days := civilDay(year, month, day) - civilDay(dyear, dmonth, dday)
+
+	var steps int
+	switch r.freq {
+	case YEARLY:
+		steps = year - dyear
+		month, day = dmonth, dday
+	case MONTHLY:
+		steps = (year-dyear)*12 + int(month-dmonth)
+		day = dday
+	case WEEKLY:
+		// Offsets from DTSTART of the week starts of t and DTSTART.
+		start := days - pymod(weekday-r.wkst, 7)
+		dstart := -pymod(toPyWeekday(r.dtstart.Weekday())-r.wkst, 7)
+		steps = (start - dstart) / 7
+		if steps == 0 {
+			// The first period starts at DTSTART rather than at the week start.
+			year, month, day = dyear, dmonth, dday
+			weekday = toPyWeekday(r.dtstart.Weekday())
+		} else {
+			year, month, day = time.Date(dyear, dmonth, dday+start, 0, 0, 0, 0, time.UTC).Date()
+			weekday = r.wkst
+		}
+	case DAILY:
+		steps = days
+	case HOURLY:
+		steps = days*24 + hour - dhour
+	case MINUTELY:
+		steps = days*1440 + hour*60 + minute - (dhour*60 + dminute)
+	case SECONDLY:
+		steps = days*86400 + hour*3600 + minute*60 + second - (dhour*3600 + dminute*60 + dsecond)
+	}
+	if pymod(steps, r.interval) != 0 ||
+		r.freq >= HOURLY && len(r.byhour) != 0 && !contains(r.byhour, hour) ||
+		r.freq >= MINUTELY && len(r.byminute) != 0 && !contains(r.byminute, minute) ||
+		r.freq >= SECONDLY && len(r.bysecond) != 0 && !contains(r.bysecond, second) {
+		return nil
+	}
+	// Fields finer than the frequency keep the values of DTSTART, like in Iterator.
+	if r.freq < SECONDLY {
+		second = dsecond
+	}
+	if r.freq < MINUTELY {
+		minute = dminute
+	}
+	if r.freq < HOURLY {
+		hour = dhour
+	}
+
+	iterator := &rIterator{ctx: context.Background()}
+	iterator.year, iterator.month, iterator.day = year, month, day
+	iterator.hour, iterator.minute, iterator.second = hour, minute, second
+	iterator.weekday = weekday
+	iterator.ii = iterInfo{rrule: r}
+	iterator.ii.rebuild(year, month)
+	if r.freq < HOURLY {
+		iterator.timeset = r.timeset
+	} else {
+		iterator.ii.fillTimeSet(&iterator.timeset, r.freq, hour, minute, second)
+	}
+	return iterator
+}
+
+// periodContains reports whether t is one of the times the current period
+// selects, regardless of DTSTART, UNTIL and COUNT.
+func (iterator *rIterator) periodContains(t time.Time) bool {
+	iterator.filterDaySet()
+	if len(iterator.ii.rrule.bysetpos) != 0 && len(iterator.timeset) != 0 {
+		return timeContains(iterator.setPositions(), t)
+	}
+	i := iterator.dayIndex(t)
+	if i < 0 || !iterator.dayset[i-iterator.dayset[0].Int].Defined {
+		return false
+	}
+	for _, timeTemp := range iterator.timeset {
+		if iterator.candidate(i, timeTemp).Equal(t) {
+			return true
+		}
+	}
+	return false
+}
+
+// dayIndex returns the day of the year of t in the current period,
+// or -1 if t is not within the period.
+func (iterator *rIterator) dayIndex(t time.Time) int {
+	if len(iterator.dayset) == 0 {
+		return -1
+	}
+	year, month, day := t.Date()
+	fyear, fmonth, fday := iterator.ii.firstyday.Date()
+	i := civilDay(year, month, day) - civilDay(fyear, fmonth, fday)
+	if j := i - iterator.dayset[0].Int; j < 0 || j >= len(iterator.dayset) {
+		return -1
+	}
+	return i
+}
+
+// civilDay returns the number of days from 1970-01-01 to the given date.
+func civilDay(year int, month time.Month, day int) int {
+	div, _ := divmod(int(time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix()), 86400)
+	return div
+}
@@ -0,0 +1,96 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestContains(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	dtstart := time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)
+	until := time.Date(1999, 3, 1, 0, 0, 0, 0, time.UTC)
+	for _, option := range []ROption{
+		{Freq: YEARLY, Count: 10, Byyearday: []int{1, 100, 200, -1}},
+		{Freq: YEARLY, Interval: 2, Until: until, Byweekno: []int{1, 20, 53}, Byweekday: []Weekday{MO}},
+		{Freq: YEARLY, Until: until, Byweekday: []Weekday{TU.Nth(1), FR.Nth(-1)}},
+		{Freq: MONTHLY, Count: 12, Byweekday: []Weekday{MO, TU, WE, TH, FR}, Bysetpos: []int{-1, 2}},
+		{Freq: MONTHLY, Interval: 3, Until: until, Bymonthday: []int{1, 15, -1}},
+		{Freq: WEEKLY, Count: 17, Interval: 2, Wkst: SU, Byweekday: []Weekday{MO, SA, SU}},
+		{Freq: WEEKLY, Until: until, Interval: 3, Byweekday: []Weekday{TU, SU}, Bysetpos: []int{1}},
+		{Freq: DAILY, Interval: 5, Until: until, Bymonth: []int{2, 9}},
+		{Freq: HOURLY, Interval: 5, Count: 100, Byhour: []int{9, 14, 19}},
+		{Freq: MINUTELY, Interval: 7, Until: time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC), Byhour: []int{9, 10}},
+		{Freq: SECONDLY, Interval: 13, Count: 300, Byminute: []int{0, 1}},
+		{Freq: DAILY, Count: 400, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, newYork)},
+	} {
+		if option.Dtstart.IsZero() {
+			option.Dtstart = dtstart
+		}
+		r, _ := NewRRule(option)
+		all := r.All()
+		if len(all) == 0 {
+			t.Fatalf("%s: no occurrences", r)
+		}
+		for _, v := range all {
+			if !r.Contains(v) {
+				t.Errorf("%s: %v should be an occurrence", r, v)
+			}
+		}
+		// Probe around the occurrences, beyond COUNT and UNTIL included.
+		last := all[len(all)-1]
+		for _, v := range all {
+			for _, d := range []time.Duration{-time.Hour, -time.Second, time.Second, time.Minute,
+				13 * time.Second, 7 * time.Minute, 5 * time.Hour, 24 * time.Hour, 7 * 24 * time.Hour} {
+				probe := v.Add(d)
+				want := timeContains(all, probe)
+				if probe.After(last) {
+					want = false
+				}
+				if got := r.Contains(probe); got != want {
+					t.Errorf("%s: Contains(%v) get %v, want %v", r, probe, got, want)
+				}
+			}
+		}
+		if r.Contains(dtstart.Add(-24 * time.Hour)) {
+			t.Errorf("%s: time before DTSTART should not be an occurrence", r)
+		}
+	}
+}
+
+func TestContainsOtherLocation(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	r, _ := NewRRule(ROption{Freq: WEEKLY, Byweekday: []Weekday{MO},
+		Dtstart: time.Date(2024, 1, 1, 20, 0, 0, 0, time.UTC)})
+	// Monday 20:00 UTC is Tuesday 05:00 in Tokyo.
+	if !r.Contains(time.Date(2024, 1, 9, 5, 0, 0, 0, tokyo)) {
+		t.Error("get false, want true")
+	}
+	if r.Contains(time.Date(2024, 1, 8, 20, 0, 0, 0, tokyo)) {
+		t.Error("get true, want false")
+	}
+}
+
+func TestSetContains(t *testing.T) {
+	set := Set{}
+	r, _ := NewRRule(ROption{Freq: WEEKLY, Count: 4,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	set.RRule(r)
+	set.RDate(time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC))
+	set.ExDate(time.Date(1997, 9, 16, 9, 0, 0, 0, time.UTC))
+	for _, c := range []struct {
+		t    time.Time
+		want bool
+	}{
+		{time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC), true},
+		{time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC), true},
+		{time.Date(1997, 9, 16, 9, 0, 0, 0, time.UTC), false},
+		{time.Date(1997, 9, 23, 9, 0, 0, 0, time.UTC), true},
+		{time.Date(1997, 9, 30, 9, 0, 0, 0, time.UTC), false},
+	} {
+		if got := set.Contains(c.t); got != c.want {
+			t.Errorf("Contains(%v): get %v, want %v", c.t, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrMaxPeriods is reported when an expansion examines more candidate periods
+// than allowed by RRule.SetMaxPeriods.
+var ErrMaxPeriods = errors.New("maximum number of candidate periods exceeded")
+
+// ExpansionError is returned when the expansion of a rule is stopped early,
+// because its context is done or because it exceeded its period budget.
+// Err is either ErrMaxPeriods or the error of the context, so it can be
+// checked with errors.Is.
+type ExpansionError struct {
+	// Periods is the number of candidate periods examined before stopping.
+	Periods int
+	Err     error
+}
+
+func (e *ExpansionError) Error() string {
+	return fmt.Sprintf("expansion stopped after %d periods: %v", e.Periods, e.Err)
+}
+
+func (e *ExpansionError) Unwrap() error {
+	return e.Err
+}
+
+// IteratorContext is like Iterator, but stops generating occurrences once ctx is done
+// or the period budget of the rule is exhausted.
+// After next returns false, err reports why: nil if the recurrence is exhausted,
+// or an *ExpansionError if the expansion was stopped early.
+func (r *RRule) IteratorContext(ctx context.Context) (next Next, err func() error) {
+	iterator := r.iterator(ctx)
+	return iterator.next, func() error { return iterator.err }
+}
+
+// AllContext is like All, but honors ctx and the period budget of the rule.
+// If the expansion is stopped early, the occurrences generated so far are
+// returned along with an *ExpansionError.
+func (r *RRule) AllContext(ctx context.Context) ([]time.Time, error) {
+	next, err := r.IteratorContext(ctx)
+	return all(next), err()
+}
+
+// BetweenContext is like Between, but honors ctx and the period budget of the rule.
+// If the expansion is stopped early, the occurrences generated so far are
+// returned along with an *ExpansionError.
+func (r *RRule) BetweenContext(ctx context.Context, after, before time.Time, inc bool) ([]time.Time, error) {
+	next, err := r.IteratorContext(ctx)
+	return between(next, after, before, inc), err()
+}
+
+// IteratorContext is like Iterator, but stops generating occurrences once ctx is done
+// or the period budget of the RRULE is exhausted.
+// After next returns false, err reports why: nil if the recurrence is exhausted,
+// or an *ExpansionError if the expansion was stopped early.
+func (set *Set) IteratorContext(ctx context.Context) (next Next, err func() error) {
+	return set.iterator(ctx)
+}
+
+// AllContext is like All, but honors ctx and the period budget of the RRULE.
+// If the expansion is stopped early, the occurrences generated so far are
+// returned along with an *ExpansionError.
+func (set *Set) AllContext(ctx context.Context) ([]time.Time, error) {
+	next, err := set.IteratorContext(ctx)
+	return all(next), err()
+}
+
+// BetweenContext is like Between, but honors ctx and the period budget of the RRULE.
+// If the expansion is stopped early, the occurrences generated so far are
+// returned along with an *ExpansionError.
+func (set *Set) BetweenContext(ctx context.Context, after, before time.Time, inc bool) ([]time.Time, error) {
+	next, err := set.IteratorContext(ctx)
+	return between(next, after, before, inc), err()
+}
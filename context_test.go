@@ -0,0 +1,105 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAllContext(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: DAILY, Count: 3,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	value, err := r.AllContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := r.All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
+
+func TestAllContextMaxPeriods(t *testing.T) {
+	// February never has a 30th day.
+	r, _ := NewRRule(ROption{Freq: YEARLY, Bymonth: []int{2}, Bymonthday: []int{30},
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	r.SetMaxPeriods(100)
+	value, err := r.AllContext(context.Background())
+	if len(value) != 0 {
+		t.Errorf("get %v, want no occurrences", value)
+	}
+	if !errors.Is(err, ErrMaxPeriods) {
+		t.Fatalf("get %v, want %v", err, ErrMaxPeriods)
+	}
+	var e *ExpansionError
+	if !errors.As(err, &e) || e.Periods != 100 {
+		t.Errorf("get %v, want an *ExpansionError after 100 periods", err)
+	}
+}
+
+func TestMaxPeriodsUnreachableHour(t *testing.T) {
+	// Stepping 24 hours from 09:00 never reaches 05:00.
+	r, _ := NewRRule(ROption{Freq: HOURLY, Interval: 24, Byhour: []int{5},
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	r.SetMaxPeriods(1000)
+	if value := r.All(); len(value) != 0 {
+		t.Errorf("get %v, want no occurrences", value)
+	}
+	_, err := r.BetweenContext(context.Background(),
+		time.Date(1997, 9, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(1998, 9, 2, 0, 0, 0, 0, time.UTC), true)
+	if !errors.Is(err, ErrMaxPeriods) {
+		t.Errorf("get %v, want %v", err, ErrMaxPeriods)
+	}
+}
+
+func TestMaxPeriodsKeptOnDTStart(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: YEARLY, Bymonth: []int{2}, Bymonthday: []int{30}})
+	r.SetMaxPeriods(10)
+	r.DTStart(time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC))
+	r.Until(time.Date(2097, 9, 2, 9, 0, 0, 0, time.UTC))
+	if _, err := r.AllContext(context.Background()); !errors.Is(err, ErrMaxPeriods) {
+		t.Errorf("get %v, want %v", err, ErrMaxPeriods)
+	}
+}
+
+func TestBetweenContextCanceled(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: SECONDLY, Byhour: []int{5},
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	value, err := r.BetweenContext(ctx,
+		time.Date(1997, 9, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(1998, 9, 2, 0, 0, 0, 0, time.UTC), true)
+	if len(value) != 0 {
+		t.Errorf("get %v, want no occurrences", value)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("get %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestSetAllContext(t *testing.T) {
+	set := Set{}
+	r, _ := NewRRule(ROption{Freq: YEARLY, Bymonth: []int{2}, Bymonthday: []int{30},
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	r.SetMaxPeriods(50)
+	set.RRule(r)
+	set.RDate(time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC))
+	value, err := set.AllContext(context.Background())
+	want := []time.Time{time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC)}
+	if !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+	if !errors.Is(err, ErrMaxPeriods) {
+		t.Errorf("get %v, want %v", err, ErrMaxPeriods)
+	}
+
+	set = Set{}
+	set.RDate(time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC))
+	if _, err := set.BetweenContext(context.Background(), time.Time{}, time.Now(), true); err != nil {
+		t.Errorf("get %v, want nil", err)
+	}
+}
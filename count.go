@@ -0,0 +1,222 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrInfinite is returned when counting the occurrences of a recurrence
+// that has neither COUNT nor UNTIL.
+var ErrInfinite = errors.New("recurrence is infinite")
+
+// IsFinite reports whether the RRule is bounded by COUNT or UNTIL.
+// Rules without either are only bounded by the default UNTIL, approximately 290 years after DTSTART.
+func (r *RRule) IsFinite() bool {
+	return r.count != 0 || !r.OrigOptions.Until.IsZero()
+}
+
+// Count returns the number of occurrences of the RRule, or ErrInfinite if it is not finite.
+// Occurrences are counted period by period without being materialized.
+func (r *RRule) Count() (int, error) {
+	if !r.IsFinite() {
+		return 0, ErrInfinite
+	}
+	return r.iterator(context.Background()).countBetween(time.Time{}, r.until, true), nil
+}
+
+// CountBetween returns the number of occurrences of the RRule between after and before,
+// that is len(r.Between(after, before, inc)), without materializing them.
+func (r *RRule) CountBetween(after, before time.Time, inc bool) int {
+	return r.iterator(context.Background()).countBetween(after, before, inc)
+}
+
+// IsFinite reports whether the rrule.Set has a finite number of occurrences,
+// that is whether it has no RRULE or a finite one.
+func (set *Set) IsFinite() bool {
+	return set.rrule == nil || set.rrule.IsFinite()
+}
+
+// Count returns the number of occurrences of the rrule.Set, or ErrInfinite if it is not finite.
+// The occurrences of the RRULE are counted period by period, and adjusted
+// for the RDATEs, EXDATEs and overrides with Contains.
+func (set *Set) Count() (int, error) {
+	if !set.IsFinite() {
+		return 0, ErrInfinite
+	}
+	n := 0
+	if set.rrule != nil {
+		n, _ = set.rrule.Count()
+	}
+	all := func(time.Time) bool { return true }
+	return n + set.countDates(all) + set.countOverrides(all), nil
+}
+
+// CountBetween returns the number of occurrences of the rrule.Set between after and before,
+// that is len(set.Between(after, before, inc)), without materializing them.
+func (set *Set) CountBetween(after, before time.Time, inc bool) int {
+	n := 0
+	if set.rrule != nil {
+		n = set.rrule.CountBetween(after, before, inc)
+	}
+	in := func(t time.Time) bool {
+		return inc && !t.Before(after) && !t.After(before) || !inc && t.After(after) && t.Before(before)
+	}
+	return n + set.countDates(in) + set.countOverrides(in)
+}
+
+// countDates returns the number of occurrences the RDATEs add to, minus the number
+// of occurrences the EXDATEs remove from, those of the RRULE, among the times accepted by in.
+func (set *Set) countDates(in func(time.Time) bool) int {
+	n := 0
+	seen := map[int64]bool{}
+	for _, rdate := range set.rdate {
+		if !in(rdate) || seen[rdate.Unix()] {
+			continue
+		}
+		seen[rdate.Unix()] = true
+		if !timeContains(set.exdate, rdate) && (set.rrule == nil || !set.rrule.Contains(rdate)) {
+			n++
+		}
+	}
+	seen = map[int64]bool{}
+	for _, exdate := range set.exdate {
+		if !in(exdate) || seen[exdate.Unix()] {
+			continue
+		}
+		seen[exdate.Unix()] = true
+		if set.rrule != nil && set.rrule.Contains(exdate) {
+			n--
+		}
+	}
+	return n
+}
+
+// countOverrides returns the number of occurrences the overrides add to, minus the number
+// of occurrences they remove from, those of the RRULE and the RDATEs, among the times accepted by in.
+func (set *Set) countOverrides(in func(time.Time) bool) int {
+	n := 0
+	applied := set.appliedOverrides()
+	seen := map[int64]bool{}
+	for _, o := range applied {
+		if in(o.RecurrenceID) {
+			n--
+		}
+		start := o.effectiveStart()
+		if !in(start) || seen[start.Unix()] {
+			continue
+		}
+		seen[start.Unix()] = true
+		if overrideOf(applied, start) != nil || !set.baseContains(start) {
+			n++
+		}
+	}
+	return n
+}
+
+// countBetween counts the remaining occurrences t of the iterator with after < t < before,
+// or after <= t <= before if inc is true. Periods whose occurrences are all on the same
+// side of a bound are counted from the filtered dayset and the timeset at once.
+func (iterator *rIterator) countBetween(after, before time.Time, inc bool) (n int) {
+	for {
+		for iterator.remain.Len() != 0 {
+			v, _ := iterator.remain.Pop()
+			if inc && v.After(before) || !inc && !v.Before(before) {
+				return n
+			}
+			if inc && !v.Before(after) || !inc && v.After(after) {
+				n++
+			}
+		}
+		if iterator.finished || !iterator.enterPeriod() {
+			return n
+		}
+		filtered := iterator.filterDaySet()
+		k, start, end, ok := iterator.wholePeriod()
+		beforeWindow := inc && end.Before(after) || !inc && !end.After(after)
+		inWindow := (inc && !start.Before(after) || !inc && start.After(after)) &&
+			(inc && !end.After(before) || !inc && end.Before(before))
+		if ok && (k == 0 || beforeWindow || inWindow) {
+			iterator.consume(k)
+			if inWindow {
+				n += k
+			}
+		} else {
+			iterator.emitPeriod()
+		}
+		if !iterator.finished {
+			iterator.advance(filtered)
+		}
+	}
+}
+
+// skip discards the next n occurrences of the iterator,
+// counting whole periods at once when possible.
+func (iterator *rIterator) skip(n int) {
+	for n > 0 {
+		if iterator.remain.Len() != 0 {
+			iterator.remain.Pop()
+			n--
+			continue
+		}
+		if iterator.finished || !iterator.enterPeriod() {
+			return
+		}
+		filtered := iterator.filterDaySet()
+		if k, _, _, ok := iterator.wholePeriod(); ok && k <= n {
+			iterator.consume(k)
+			n -= k
+		} else {
+			iterator.emitPeriod()
+		}
+		if !iterator.finished {
+			iterator.advance(filtered)
+		}
+	}
+}
+
+// wholePeriod returns the number of occurrences of the current period, and the first
+// and last of them, computed from the filtered dayset and the timeset without generating them.
+// It returns false if the period has to be generated occurrence by occurrence,
+// because it holds a BYSETPOS selection or crosses DTSTART, UNTIL or COUNT.
+func (iterator *rIterator) wholePeriod() (k int, start, end time.Time, ok bool) {
+	r := iterator.ii.rrule
+	if len(r.bysetpos) != 0 && len(iterator.timeset) != 0 {
+		return 0, start, end, false
+	}
+
+	first, last, days := -1, -1, 0
+	for _, day := range iterator.dayset {
+		if day.Defined {
+			if first < 0 {
+				first = day.Int
+			}
+			last = day.Int
+			days++
+		}
+	}
+	k = days * len(iterator.timeset)
+	if k == 0 {
+		return 0, start, end, true
+	}
+	if iterator.count != 0 && k >= iterator.count {
+		return 0, start, end, false
+	}
+
+	start = iterator.candidate(first, iterator.timeset[0])
+	end = iterator.candidate(last, iterator.timeset[len(iterator.timeset)-1])
+	if start.Before(r.dtstart) || end.After(r.until) {
+		return 0, start, end, false
+	}
+	return k, start, end, true
+}
+
+// consume accounts for k occurrences counted without being generated.
+func (iterator *rIterator) consume(k int) {
+	iterator.total += k
+	if iterator.count != 0 {
+		iterator.count -= k
+	}
+}
@@ -0,0 +1,127 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsFinite(t *testing.T) {
+	dtstart := time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		option ROption
+		finite bool
+	}{
+		{ROption{Freq: DAILY, Dtstart: dtstart}, false},
+		{ROption{Freq: DAILY, Dtstart: dtstart, Count: 3}, true},
+		{ROption{Freq: DAILY, Dtstart: dtstart, Until: dtstart.AddDate(1, 0, 0)}, true},
+	} {
+		r, _ := NewRRule(c.option)
+		if r.IsFinite() != c.finite {
+			t.Errorf("%s: get %v, want %v", r, r.IsFinite(), c.finite)
+		}
+		set := Set{}
+		set.RRule(r)
+		if set.IsFinite() != c.finite {
+			t.Errorf("set %s: get %v, want %v", r, set.IsFinite(), c.finite)
+		}
+	}
+
+	if _, err := (&Set{}).Count(); err != nil {
+		t.Errorf("empty set: get %v, want nil", err)
+	}
+	r, _ := NewRRule(ROption{Freq: DAILY, Dtstart: dtstart})
+	if _, err := r.Count(); err != ErrInfinite {
+		t.Errorf("get %v, want %v", err, ErrInfinite)
+	}
+}
+
+func TestCount(t *testing.T) {
+	dtstart := time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)
+	until := time.Date(2000, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, option := range []ROption{
+		{Freq: YEARLY, Count: 10, Byyearday: []int{1, 100, 200, -1}},
+		{Freq: YEARLY, Until: until, Byweekday: []Weekday{MO, FR}, Byhour: []int{9, 18}},
+		{Freq: YEARLY, Until: until, Byweekno: []int{1, 20, 53}, Byweekday: []Weekday{MO}},
+		{Freq: MONTHLY, Count: 25, Byweekday: []Weekday{MO, TU, WE, TH, FR}, Bysetpos: []int{-1}},
+		{Freq: MONTHLY, Until: until, Bymonthday: []int{1, 15, -1}},
+		{Freq: WEEKLY, Count: 17, Interval: 2, Byweekday: []Weekday{TU, TH}},
+		{Freq: DAILY, Until: until, Bymonth: []int{2, 4}},
+		{Freq: HOURLY, Count: 100, Byhour: []int{9, 10, 11}, Byminute: []int{0, 30}},
+		{Freq: MINUTELY, Until: time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC), Interval: 7},
+		{Freq: SECONDLY, Count: 300, Bysecond: []int{0, 15}},
+	} {
+		option.Dtstart = dtstart
+		r, _ := NewRRule(option)
+		all := r.All()
+		n, err := r.Count()
+		if err != nil || n != len(all) {
+			t.Errorf("%s: get %d %v, want %d", r, n, err, len(all))
+		}
+
+		for _, w := range [][2]time.Time{
+			{dtstart, until},
+			{dtstart.AddDate(0, 2, 3), dtstart.AddDate(1, 1, 0)},
+			{dtstart.Add(90 * time.Minute), dtstart.Add(26 * time.Hour)},
+			{all[len(all)/2], all[len(all)-1]},
+		} {
+			for _, inc := range []bool{true, false} {
+				want := len(r.Between(w[0], w[1], inc))
+				if n := r.CountBetween(w[0], w[1], inc); n != want {
+					t.Errorf("%s between %v and %v (inc %v): get %d, want %d", r, w[0], w[1], inc, n, want)
+				}
+			}
+		}
+	}
+}
+
+func TestCountInfiniteBetween(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: MINUTELY, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	n := r.CountBetween(time.Date(1998, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(1998, 1, 2, 0, 0, 0, 0, time.UTC), false)
+	if n != 1439 {
+		t.Errorf("get %d, want 1439", n)
+	}
+}
+
+func TestSetCount(t *testing.T) {
+	set := Set{}
+	r, _ := NewRRule(ROption{Freq: WEEKLY, Count: 4,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	set.RRule(r)
+	if n, err := set.Count(); err != nil || n != 4 {
+		t.Errorf("get %d %v, want 4", n, err)
+	}
+	set.RDate(time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC))
+	set.RDate(time.Date(1997, 9, 9, 9, 0, 0, 0, time.UTC))
+	set.RDate(time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC))
+	set.RDate(time.Date(1997, 9, 30, 9, 0, 0, 0, time.UTC))
+	set.ExDate(time.Date(1997, 9, 30, 9, 0, 0, 0, time.UTC))
+	set.ExDate(time.Date(1997, 9, 16, 9, 0, 0, 0, time.UTC))
+	if n, err := set.Count(); err != nil || n != 4 {
+		t.Errorf("get %d %v, want 4", n, err)
+	}
+	after := time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC)
+	before := time.Date(1997, 9, 23, 9, 0, 0, 0, time.UTC)
+	for _, inc := range []bool{true, false} {
+		if n, want := set.CountBetween(after, before, inc), len(set.Between(after, before, inc)); n != want {
+			t.Errorf("inc %v: get %d, want %d", inc, n, want)
+		}
+	}
+}
+
+func BenchmarkCount(b *testing.B) {
+	r, _ := NewRRule(ROption{Freq: DAILY, Byhour: []int{9, 12, 15, 18}, Byminute: []int{0, 30},
+		Dtstart: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		Until:   time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)})
+	b.Run("All", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = len(r.All())
+		}
+	})
+	b.Run("Count", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, _ = r.Count()
+		}
+	})
+}
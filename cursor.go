@@ -0,0 +1,248 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"sort"
+	"time"
+)
+
+// ErrInvalidCursor is returned by Page when the cursor is malformed,
+// or does not point to an occurrence of the recurrence.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is an opaque position in the occurrences of an RRule or a rrule.Set,
+// returned by Page to fetch the following page. It is a URL-safe string
+// that can be handed to clients and sent back as is.
+// The empty cursor is the position before the first occurrence.
+type Cursor string
+
+// cursorState is the decoded form of a Cursor. rule is the fingerprint of the RRULE,
+// see RRule.Resume. last is the last occurrence returned. anchor is the last occurrence
+// of the RRULE up to last, and index its position in the RRULE, the iterator of the RRULE
+// resumes from there.
+type cursorState struct {
+	rule      uint64
+	last      time.Time
+	anchor    time.Time
+	index     int
+	hasAnchor bool
+}
+
+const cursorVersion = 2
+
+func (c cursorState) encode() Cursor {
+	buf := make([]byte, 9, 9+3*binary.MaxVarintLen64)
+	buf[0] = cursorVersion
+	binary.BigEndian.PutUint64(buf[1:], c.rule)
+	tmp := make([]byte, binary.MaxVarintLen64)
+	buf = append(buf, tmp[:binary.PutVarint(tmp, c.last.Unix())]...)
+	if c.hasAnchor {
+		buf = append(buf, tmp[:binary.PutUvarint(tmp, uint64(c.index)+1)]...)
+		buf = append(buf, tmp[:binary.PutUvarint(tmp, uint64(c.last.Unix()-c.anchor.Unix()))]...)
+	} else {
+		buf = append(buf, 0)
+	}
+	return Cursor(base64.RawURLEncoding.EncodeToString(buf))
+}
+
+// decode decodes the cursor, and reports whether it is not the empty cursor.
+func (cursor Cursor) decode() (c cursorState, started bool, err error) {
+	if cursor == "" {
+		return c, false, nil
+	}
+	buf, err := base64.RawURLEncoding.DecodeString(string(cursor))
+	if err != nil || len(buf) < 9 || buf[0] != cursorVersion {
+		return c, false, ErrInvalidCursor
+	}
+	c.rule = binary.BigEndian.Uint64(buf[1:])
+	buf = buf[9:]
+	last, n := binary.Varint(buf)
+	if n <= 0 {
+		return c, false, ErrInvalidCursor
+	}
+	buf = buf[n:]
+	index, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return c, false, ErrInvalidCursor
+	}
+	buf = buf[n:]
+	c.last = time.Unix(last, 0).UTC()
+	if index != 0 {
+		delta, n := binary.Uvarint(buf)
+		if n <= 0 || index > 1<<31 {
+			return c, false, ErrInvalidCursor
+		}
+		buf = buf[n:]
+		c.anchor, c.index, c.hasAnchor = time.Unix(last-int64(delta), 0).UTC(), int(index-1), true
+	}
+	if len(buf) != 0 {
+		return c, false, ErrInvalidCursor
+	}
+	return c, true, nil
+}
+
+// Page returns the next n occurrences of the RRule after cursor, and the cursor of the
+// following page, empty if there are no more occurrences. Pass the empty cursor for the
+// first page. The iterator resumes at the period of the last occurrence, without
+// generating the occurrences before it.
+//
+// ErrInvalidCursor is returned if the cursor was issued for another rule, or for the rule
+// before it changed, or if its last occurrence is not an occurrence of the rule.
+func (r *RRule) Page(cursor Cursor, n int) ([]time.Time, Cursor, error) {
+	c, started, err := cursor.decode()
+	if err != nil {
+		return nil, "", err
+	}
+	fingerprint := r.fingerprint()
+	if started && c.rule != fingerprint {
+		return nil, "", ErrInvalidCursor
+	}
+	if n <= 0 {
+		return []time.Time{}, cursor, nil
+	}
+	iterator, index := r.iterator(context.Background()), 0
+	if started {
+		if !c.hasAnchor || !c.anchor.Equal(c.last) {
+			return nil, "", ErrInvalidCursor
+		}
+		if iterator, index = r.iteratorAfter(c.last, c.index), c.index+1; iterator == nil {
+			return nil, "", ErrInvalidCursor
+		}
+	}
+	result, more := page(iterator.next, n)
+	if !more {
+		return result, "", nil
+	}
+	last := result[len(result)-1]
+	return result, cursorState{rule: fingerprint, last: last, anchor: last, index: index + n - 1, hasAnchor: true}.encode(), nil
+}
+
+// Page returns the next n occurrences of the rrule.Set after cursor, and the cursor of the
+// following page, empty if there are no more occurrences. Pass the empty cursor for the
+// first page. Occurrences are in the order of Iterator, where duplicate times of the RRULE,
+// the RDATEs and the overrides are a single occurrence. The following page starts strictly
+// after the last occurrence of the cursor: the iterator of the RRULE resumes at the period
+// of its last occurrence up to that time, RDATEs and overrides from that time,
+// and EXDATEs apply as usual.
+//
+// ErrInvalidCursor is returned if the last occurrence of the cursor is not an occurrence
+// of the set, or the RRULE changed since the cursor was issued.
+func (set *Set) Page(cursor Cursor, n int) ([]time.Time, Cursor, error) {
+	c, started, err := cursor.decode()
+	if err != nil {
+		return nil, "", err
+	}
+	fingerprint := uint64(0)
+	if set.rrule != nil {
+		fingerprint = set.rrule.fingerprint()
+	}
+	if started && (c.rule != fingerprint || !set.Contains(c.last)) {
+		return nil, "", ErrInvalidCursor
+	}
+	if n <= 0 {
+		return []time.Time{}, cursor, nil
+	}
+
+	sort.Sort(timeSlice(set.rdate))
+	rdates := set.rdate
+	applied := set.appliedOverrides()
+	moved := applied
+	if started {
+		rdates = rdates[sort.Search(len(rdates), func(i int) bool { return rdates[i].After(c.last) }):]
+		moved = moved[sort.Search(len(moved), func(i int) bool { return moved[i].effectiveStart().After(c.last) }):]
+	}
+
+	// The occurrences of the RRULE are recorded to find the anchor of the next cursor.
+	var rule Next
+	var pulled []time.Time
+	first := 0
+	if set.rrule != nil {
+		iterator := set.rrule.iterator(context.Background())
+		if c.hasAnchor {
+			if iterator, first = set.rrule.iteratorAfter(c.anchor, c.index), c.index+1; iterator == nil {
+				return nil, "", ErrInvalidCursor
+			}
+		}
+		rule = func() (time.Time, bool) {
+			v, ok := iterator.next()
+			if ok {
+				pulled = append(pulled, v)
+			}
+			return v, ok
+		}
+	}
+	occurrences := set.withOverrides(set.mergeBase(rule, first, rdates), applied, moved)
+	result, more := page(func() (time.Time, bool) {
+		o, ok := occurrences()
+		return o.Time, ok
+	}, n)
+	if !more {
+		return result, "", nil
+	}
+
+	next := cursorState{rule: fingerprint, last: result[len(result)-1]}
+	if i := sort.Search(len(pulled), func(i int) bool { return pulled[i].After(next.last) }); i > 0 {
+		next.anchor, next.index, next.hasAnchor = pulled[i-1], first+i-1, true
+	} else if c.hasAnchor {
+		next.anchor, next.index, next.hasAnchor = c.anchor, c.index, true
+	}
+	return result, next.encode(), nil
+}
+
+// page returns the next n times of next, and whether more times follow them.
+func page(next Next, n int) ([]time.Time, bool) {
+	result := []time.Time{}
+	for len(result) < n {
+		v, ok := next()
+		if !ok {
+			return result, false
+		}
+		result = append(result, v)
+	}
+	_, more := next()
+	return result, more
+}
+
+// iteratorAfter returns an iterator positioned right after t, the occurrence of the rule
+// at the zero-based position i, with its total and count restored,
+// or nil if t is not an occurrence of the rule.
+func (r *RRule) iteratorAfter(t time.Time, i int) *rIterator {
+	t = t.In(r.dtstart.Location())
+	if i < 0 || r.count != 0 && i >= r.count || t.Before(r.dtstart) || t.After(r.until) {
+		return nil
+	}
+	iterator := r.iteratorAt(t)
+	if iterator == nil {
+		return nil
+	}
+
+	// Generate the whole period regardless of COUNT, and drop the occurrences up to t.
+	filtered := iterator.filterDaySet()
+	iterator.emitPeriod()
+	found := false
+	for iterator.remain.Len() != 0 && !iterator.remain.storage[0].After(t) {
+		v, _ := iterator.remain.Pop()
+		found = v.Equal(t)
+	}
+	if !found {
+		return nil
+	}
+	iterator.total = i + 1 + iterator.remain.Len()
+	if r.count != 0 {
+		if left := r.count - i - 1; left <= iterator.remain.Len() {
+			iterator.remain.storage = iterator.remain.storage[:left]
+			iterator.finished = true
+		} else {
+			iterator.count = left - iterator.remain.Len()
+		}
+	}
+	if !iterator.finished {
+		iterator.advance(filtered)
+	}
+	return iterator
+}
@@ -0,0 +1,126 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+// allPages concatenates the pages of n occurrences until the last one.
+func allPages(t *testing.T, page func(Cursor, int) ([]time.Time, Cursor, error), n int) []time.Time {
+	var result []time.Time
+	cursor := Cursor("")
+	for i := 0; i < 1000; i++ {
+		value, next, err := page(cursor, n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(value) > n || next != "" && len(value) != n {
+			t.Fatalf("page of %d occurrences for %d", len(value), n)
+		}
+		result = append(result, value...)
+		if next == "" {
+			return result
+		}
+		cursor = next
+	}
+	t.Fatal("too many pages")
+	return nil
+}
+
+func TestRRulePage(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	for _, option := range []ROption{
+		{Freq: DAILY, Count: 10, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)},
+		{Freq: WEEKLY, Count: 11, Byweekday: []Weekday{TU, TH}, Byhour: []int{9, 17}, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)},
+		{Freq: MONTHLY, Count: 7, Byweekday: []Weekday{MO, TU, WE, TH, FR}, Bysetpos: []int{-1, 1}, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)},
+		{Freq: YEARLY, Until: time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC), Bymonth: []int{1, 3}, Bymonthday: []int{1, -1}, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)},
+		{Freq: WEEKLY, Count: 12, Byweekday: []Weekday{SA, SU}, Dtstart: time.Date(2024, 10, 26, 1, 30, 0, 0, newYork)},
+		{Freq: HOURLY, Interval: 5, Count: 9, Byhour: []int{0, 5, 10}, Dtstart: time.Date(1997, 9, 2, 0, 0, 0, 0, time.UTC)},
+	} {
+		r, err := NewRRule(option)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := r.All()
+		for n := 1; n <= 5; n++ {
+			if value := allPages(t, r.Page, n); !timesEqual(value, want) {
+				t.Errorf("%v by %d: get %v, want %v", option, n, value, want)
+			}
+		}
+	}
+}
+
+func TestSetPage(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: DAILY, Count: 8, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	set := &Set{}
+	set.RRule(r)
+	// A duplicate of an occurrence of the RRULE, and an extra date.
+	set.RDate(time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC))
+	set.RDate(time.Date(1997, 9, 4, 12, 0, 0, 0, time.UTC))
+	set.ExDate(time.Date(1997, 9, 6, 9, 0, 0, 0, time.UTC))
+	// Occurrences moved forward and backward across pages.
+	set.Override(Override{RecurrenceID: time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC), Start: time.Date(1997, 9, 8, 12, 0, 0, 0, time.UTC)})
+	set.Override(Override{RecurrenceID: time.Date(1997, 9, 9, 9, 0, 0, 0, time.UTC), Start: time.Date(1997, 9, 2, 12, 0, 0, 0, time.UTC)})
+	want := set.All()
+	for n := 1; n <= 4; n++ {
+		if value := allPages(t, set.Page, n); !timesEqual(value, want) {
+			t.Errorf("by %d: get %v, want %v", n, value, want)
+		}
+	}
+
+	// Sets of RDATEs only.
+	set = &Set{}
+	set.RDate(time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC))
+	set.RDate(time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC))
+	if value, want := allPages(t, set.Page, 1), set.All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
+
+func TestInvalidCursor(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: DAILY, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	value, cursor, err := r.Page("", 3)
+	if err != nil || len(value) != 3 || cursor == "" {
+		t.Fatalf("get %v %q %v", value, cursor, err)
+	}
+	if _, _, err := r.Page("garbage", 3); err != ErrInvalidCursor {
+		t.Errorf("get %v, want ErrInvalidCursor", err)
+	}
+	// The rule changed since the cursor was issued.
+	r.DTStart(time.Date(1997, 9, 2, 10, 0, 0, 0, time.UTC))
+	if _, _, err := r.Page(cursor, 3); err != ErrInvalidCursor {
+		t.Errorf("get %v, want ErrInvalidCursor", err)
+	}
+	set := &Set{}
+	set.RRule(r)
+	if _, _, err := set.Page(cursor, 3); err != ErrInvalidCursor {
+		t.Errorf("get %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestCursorOfChangedRule(t *testing.T) {
+	r, _ := StrToRRule("DTSTART:19970902T090000Z\nRRULE:FREQ=DAILY;COUNT=5")
+	_, cursor, _ := r.Page("", 2)
+	// The changed rule still produces the last occurrence of the cursor, at another position.
+	changed, _ := StrToRRule("DTSTART:19970901T090000Z\nRRULE:FREQ=DAILY;COUNT=5")
+	if !changed.Contains(time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Fatal("the changed rule does not produce the occurrence")
+	}
+	if _, _, err := changed.Page(cursor, 3); err != ErrInvalidCursor {
+		t.Errorf("get %v, want ErrInvalidCursor", err)
+	}
+
+	set, _ := StrToRRuleSet("DTSTART:19970902T090000Z\nRRULE:FREQ=DAILY;COUNT=5\nRDATE:19970910T090000Z")
+	_, cursor, _ = set.Page("", 2)
+	changedSet, _ := StrToRRuleSet("DTSTART:19970901T090000Z\nRRULE:FREQ=DAILY;COUNT=5\nRDATE:19970910T090000Z")
+	if _, _, err := changedSet.Page(cursor, 3); err != ErrInvalidCursor {
+		t.Errorf("get %v, want ErrInvalidCursor", err)
+	}
+	// RDATEs and EXDATEs may change, the cursor is kept.
+	set.ExDate(time.Date(1997, 9, 5, 9, 0, 0, 0, time.UTC))
+	if value, _, err := set.Page(cursor, 3); err != nil || len(value) != 3 || !value[0].Equal(time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("get %v %v", value, err)
+	}
+}
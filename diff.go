@@ -0,0 +1,172 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Move is an occurrence that moved from one time to another between two versions of a Set.
+type Move struct {
+	From time.Time
+	To   time.Time
+}
+
+// Changes are the differences between the occurrences of two versions of a Set.
+type Changes struct {
+	// Added are the occurrences of the new version that are not in the old one.
+	Added []time.Time
+	// Removed are the occurrences of the old version that are not in the new one.
+	Removed []time.Time
+	// Moved are the occurrences that changed time, they are neither added nor removed.
+	Moved []Move
+}
+
+// Diff returns the changes of the occurrences t with after <= t < before
+// between the old and the updated version of a Set. The occurrences of both versions
+// are streamed in parallel, and only the differences are kept.
+//
+// An occurrence that disappeared and one that appeared are reported as moved
+// if they have the same RECURRENCE-ID, typically when an override moved the occurrence.
+// The remaining ones are reported as moved if they all differ by the same shift,
+// typically when DTSTART was moved, occurrences shifted across after or before excepted.
+func Diff(old, updated *Set, after, before time.Time) Changes {
+	var removed, added []Occurrence
+	next1, _ := old.occurrences(context.Background())
+	next2, _ := updated.occurrences(context.Background())
+	o1, ok1 := skipOccurrences(next1, after)
+	o2, ok2 := skipOccurrences(next2, after)
+	ok1 = ok1 && o1.Time.Before(before)
+	ok2 = ok2 && o2.Time.Before(before)
+	for ok1 || ok2 {
+		switch {
+		case ok1 && ok2 && o1.Time.Equal(o2.Time):
+			o1, ok1 = next1()
+			o2, ok2 = next2()
+		case ok1 && (!ok2 || o1.Time.Before(o2.Time)):
+			removed = append(removed, o1)
+			o1, ok1 = next1()
+		default:
+			added = append(added, o2)
+			o2, ok2 = next2()
+		}
+		ok1 = ok1 && o1.Time.Before(before)
+		ok2 = ok2 && o2.Time.Before(before)
+	}
+
+	changes := Changes{}
+	removed, added = changes.moveByRecurrenceID(removed, added)
+	changes.moveByShift(old, updated, removed, added, after, before)
+	sort.Slice(changes.Moved, func(i, j int) bool { return changes.Moved[i].From.Before(changes.Moved[j].From) })
+	return changes
+}
+
+// moveByRecurrenceID pairs the removed and added occurrences with the same RECURRENCE-ID
+// as moves, and returns the others.
+func (c *Changes) moveByRecurrenceID(removed, added []Occurrence) (restRemoved, restAdded []Occurrence) {
+	byID := map[int64]int{}
+	for i, o := range added {
+		byID[o.RecurrenceID.Unix()] = i
+	}
+	paired := make([]bool, len(added))
+	for _, o := range removed {
+		if i, ok := byID[o.RecurrenceID.Unix()]; ok && !paired[i] && added[i].RecurrenceID.Equal(o.RecurrenceID) {
+			paired[i] = true
+			c.Moved = append(c.Moved, Move{From: o.Time, To: added[i].Time})
+		} else {
+			restRemoved = append(restRemoved, o)
+		}
+	}
+	for i, o := range added {
+		if !paired[i] {
+			restAdded = append(restAdded, o)
+		}
+	}
+	return restRemoved, restAdded
+}
+
+// moveByShift reports the removed and added occurrences as moves if they all
+// differ by the same shift, those shifted out of [after, before) excepted,
+// and as removed and added otherwise.
+func (c *Changes) moveByShift(old, updated *Set, removed, added []Occurrence, after, before time.Time) {
+	shift, shifted := findShift(old, updated, removed, added, after, before)
+	in := func(t time.Time) bool { return !t.Before(after) && t.Before(before) }
+	for _, o := range removed {
+		if to := o.Time.Add(shift); shifted && in(to) {
+			c.Moved = append(c.Moved, Move{From: o.Time, To: to})
+		} else {
+			c.Removed = append(c.Removed, o.Time)
+		}
+	}
+	for _, o := range added {
+		if !shifted || !in(o.Time.Add(-shift)) {
+			c.Added = append(c.Added, o.Time)
+		}
+	}
+}
+
+// findShift looks for a shift that maps every removed occurrence to an added one
+// and every added occurrence to a removed one. Images out of [after, before) were not
+// streamed, they are checked against the other version of the set instead.
+// The shifts of the first removed occurrence and to the first added occurrence are tried,
+// and the smallest one that fits is kept.
+func findShift(old, updated *Set, removed, added []Occurrence, after, before time.Time) (time.Duration, bool) {
+	if len(removed) == 0 || len(added) == 0 {
+		return 0, false
+	}
+	in := func(t time.Time) bool { return !t.Before(after) && t.Before(before) }
+	times := func(occurrences []Occurrence) map[int64]bool {
+		m := map[int64]bool{}
+		for _, o := range occurrences {
+			m[o.Time.Unix()] = true
+		}
+		return m
+	}
+	removedTimes, addedTimes := times(removed), times(added)
+	verify := func(shift time.Duration) bool {
+		for _, o := range removed {
+			if to := o.Time.Add(shift); in(to) && !addedTimes[to.Unix()] || !in(to) && !updated.Contains(to) {
+				return false
+			}
+		}
+		for _, o := range added {
+			if from := o.Time.Add(-shift); in(from) && !removedTimes[from.Unix()] || !in(from) && !old.Contains(from) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var best time.Duration
+	found := false
+	try := func(shift time.Duration) {
+		abs := func(d time.Duration) time.Duration {
+			if d < 0 {
+				return -d
+			}
+			return d
+		}
+		if (!found || abs(shift) < abs(best)) && verify(shift) {
+			best, found = shift, true
+		}
+	}
+	for _, o := range added {
+		try(o.Time.Sub(removed[0].Time))
+	}
+	for _, o := range removed {
+		try(added[0].Time.Sub(o.Time))
+	}
+	return best, found
+}
+
+// skipOccurrences returns the first occurrence of next that is not before t.
+func skipOccurrences(next func() (Occurrence, bool), t time.Time) (Occurrence, bool) {
+	for {
+		o, ok := next()
+		if !ok || !o.Time.Before(t) {
+			return o, ok
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func diffSet(option ROption) *Set {
+	r, _ := NewRRule(option)
+	set := &Set{}
+	set.RRule(r)
+	return set
+}
+
+func TestDiffAddedRemoved(t *testing.T) {
+	old := diffSet(ROption{Freq: WEEKLY, Byweekday: []Weekday{MO, WE}, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)})
+	new := diffSet(ROption{Freq: WEEKLY, Byweekday: []Weekday{MO, TU, FR}, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)})
+	changes := Diff(old, new, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC))
+	want := Changes{
+		Added:   []time.Time{time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)},
+		Removed: []time.Time{time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("get %+v, want %+v", changes, want)
+	}
+}
+
+func TestDiffOverride(t *testing.T) {
+	old := diffSet(ROption{Freq: DAILY, Count: 5, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)})
+	new := old.clone()
+	new.Override(Override{RecurrenceID: time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), Start: time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)})
+	new, _ = new.DeleteInstance(time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC))
+	changes := Diff(old, new, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC))
+	want := Changes{
+		Removed: []time.Time{time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC)},
+		Moved:   []Move{{time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("get %+v, want %+v", changes, want)
+	}
+}
+
+func TestDiffShift(t *testing.T) {
+	// Moving a weekly meeting from Wednesday to Friday moves every occurrence.
+	wednesday := diffSet(ROption{Freq: WEEKLY, Dtstart: time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)})
+	friday := diffSet(ROption{Freq: WEEKLY, Dtstart: time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)})
+	if changes := Diff(wednesday, friday, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)); len(changes.Moved) != 2 || len(changes.Added)+len(changes.Removed) != 0 {
+		t.Errorf("get %+v, want two moves", changes)
+	}
+
+	old := diffSet(ROption{Freq: DAILY, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)})
+	new := diffSet(ROption{Freq: DAILY, Dtstart: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)})
+	// The occurrence of 2024-01-03 is moved out of the window, that of 2024-01-01 into it.
+	changes := Diff(old, new, time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC), time.Date(2024, 1, 3, 9, 30, 0, 0, time.UTC))
+	want := Changes{
+		Added:   []time.Time{time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+		Removed: []time.Time{time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)},
+		Moved:   []Move{{time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)}},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("get %+v, want %+v", changes, want)
+	}
+
+	if changes := Diff(old, old, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); !reflect.DeepEqual(changes, Changes{}) {
+		t.Errorf("get %+v, want no change", changes)
+	}
+}
@@ -0,0 +1,190 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotOccurrence is returned by the editing operations of Set
+// when the given time is not an occurrence of the set.
+var ErrNotOccurrence = errors.New("time is not an occurrence of the set")
+
+// SplitAt splits the set at the occurrence t, like editing "this and following"
+// occurrences of a calendar series. head holds the occurrences before t and tail
+// the occurrences from t on, so that together they produce the occurrences of the set.
+// t is either the original time of an occurrence or the effective time of an override.
+// The set itself is not modified.
+//
+// The RRULE of head ends before t, with COUNT reduced to the number of occurrences
+// of the rule before t if it has one, and with UNTIL set to the last of them otherwise.
+// The RRULE of tail starts at the first occurrence of the rule from t on,
+// with the remaining COUNT, or with the UNTIL of the rule, explicit or default.
+// The BYxxx parts inferred from the original DTSTART are written explicitly
+// in the tail rule, so that moving DTSTART does not change them. The tail of
+// a WEEKLY rule with BYSETPOS starts at the beginning of the week instead,
+// or at the original DTSTART in its first week, with EXDATEs for the occurrences
+// of that week before t.
+// RDATEs, EXDATEs and overrides are partitioned at t by their original time.
+func (set *Set) SplitAt(t time.Time) (head, tail *Set, err error) {
+	id, ok := set.recurrenceID(t)
+	if !ok {
+		return nil, nil, ErrNotOccurrence
+	}
+
+	head, tail = set.clone(), set.clone()
+	head.rdate, tail.rdate = partitionTimes(set.rdate, id)
+	head.exdate, tail.exdate = partitionTimes(set.exdate, id)
+	head.overrides, tail.overrides = nil, nil
+	for _, o := range set.overrides {
+		if o.RecurrenceID.Before(id) {
+			head.overrides = append(head.overrides, o)
+		} else {
+			tail.overrides = append(tail.overrides, o)
+		}
+	}
+	tail.dtstart = id
+	if set.rrule == nil {
+		return head, tail, nil
+	}
+
+	r := set.rrule
+	n := r.CountBetween(time.Time{}, id, false)
+	if n == 0 {
+		head.rrule = nil
+	} else {
+		option := r.OrigOptions
+		if r.count != 0 {
+			option.Count = n
+		} else {
+			option.Until = r.Before(id, false)
+		}
+		head.rrule = r.withOptions(option)
+	}
+
+	start := r.After(id, true)
+	if start.IsZero() {
+		tail.rrule = nil
+		return head, tail, nil
+	}
+	option := r.OrigOptions
+	option.Dtstart = start
+	option.Bymonth, option.Bymonthday, option.Byweekday = r.Options.Bymonth, r.Options.Bymonthday, r.Options.Byweekday
+	if r.count != 0 {
+		// The occurrences the rule still produces before its end.
+		option.Count = r.CountBetween(start, r.until, true)
+	} else if option.Until.IsZero() {
+		// The default end depends on DTSTART, keep the one of the rule.
+		option.Until = r.until
+	}
+	if r.freq == WEEKLY && len(r.bysetpos) != 0 {
+		// The first week of a WEEKLY rule only holds the days from DTSTART on,
+		// which would shift BYSETPOS. Start at the beginning of the week instead
+		// and exclude the occurrences before the split.
+		year, month, day := start.Date()
+		hour, minute, second := start.Clock()
+		offset := pymod(toPyWeekday(start.Weekday())-r.wkst, 7)
+		option.Dtstart = time.Date(year, month, day-offset, hour, minute, second, 0, start.Location())
+		if option.Dtstart.Before(r.dtstart) {
+			// The first week of the rule itself.
+			option.Dtstart = r.dtstart
+		}
+		until := option
+		until.Count = 0
+		for _, v := range r.withOptions(until).Between(option.Dtstart, start, true) {
+			if v.Before(start) {
+				tail.exdate = append(tail.exdate, v)
+				if option.Count != 0 {
+					option.Count++
+				}
+			}
+		}
+	}
+	tail.rrule = r.withOptions(option)
+	tail.dtstart = tail.rrule.dtstart
+	return head, tail, nil
+}
+
+// TruncateAt returns a copy of the set that ends before the occurrence t,
+// like deleting "this and following" occurrences of a calendar series.
+// It is the head returned by SplitAt.
+func (set *Set) TruncateAt(t time.Time) (*Set, error) {
+	head, _, err := set.SplitAt(t)
+	return head, err
+}
+
+// DeleteInstance returns a copy of the set without the occurrence t,
+// given by its original time or by the effective time of an override.
+// The override of the occurrence is removed, and the occurrence is removed from
+// the RDATEs if it is one, and excluded with an EXDATE if the RRULE still produces it.
+func (set *Set) DeleteInstance(t time.Time) (*Set, error) {
+	id, ok := set.recurrenceID(t)
+	if !ok {
+		return nil, ErrNotOccurrence
+	}
+
+	result := set.clone()
+	result.overrides = nil
+	for _, o := range set.overrides {
+		if !o.RecurrenceID.Equal(id) {
+			result.overrides = append(result.overrides, o)
+		}
+	}
+	result.rdate = nil
+	for _, rdate := range set.rdate {
+		if !rdate.Equal(id) {
+			result.rdate = append(result.rdate, rdate)
+		}
+	}
+	if result.baseContains(id) {
+		result.ExDate(id)
+	}
+	return result, nil
+}
+
+// recurrenceID returns the original time of the occurrence t
+// of the set, given by its original time or by the effective time of an override.
+func (set *Set) recurrenceID(t time.Time) (time.Time, bool) {
+	applied := set.appliedOverrides()
+	for _, o := range applied {
+		if o.effectiveStart().Equal(t) {
+			return o.RecurrenceID, true
+		}
+	}
+	return t, set.baseContains(t)
+}
+
+// clone returns a copy of the set that shares nothing mutable with it.
+func (set *Set) clone() *Set {
+	result := *set
+	result.rdate = append([]time.Time(nil), set.rdate...)
+	result.exdate = append([]time.Time(nil), set.exdate...)
+	result.overrides = append([]Override(nil), set.overrides...)
+	if set.rrule != nil {
+		r := *set.rrule
+		result.rrule = &r
+	}
+	return &result
+}
+
+// withOptions returns a rule built from option, keeping the settings
+// of r that are not part of the options.
+func (r *RRule) withOptions(option ROption) *RRule {
+	result := *r
+	result.OrigOptions = option
+	result.rebuild()
+	return &result
+}
+
+// partitionTimes splits times into those before t and the others.
+func partitionTimes(times []time.Time, t time.Time) (before, after []time.Time) {
+	for _, v := range times {
+		if v.Before(t) {
+			before = append(before, v)
+		} else {
+			after = append(after, v)
+		}
+	}
+	return before, after
+}
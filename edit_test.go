@@ -0,0 +1,159 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitAt(t *testing.T) {
+	set, _ := StrToRRuleSet("DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=MONTHLY;COUNT=6\nRDATE;TZID=America/New_York:19970915T090000\nEXDATE;TZID=America/New_York:19971202T090000")
+	all := set.All()
+	split := all[3]
+	head, tail, err := set.SplitAt(split)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := append(head.All(), tail.All()...); !timesEqual(value, all) {
+		t.Errorf("get %v, want %v", value, all)
+	}
+	if value := head.GetRRule().OrigOptions.Count; value != 2 {
+		t.Errorf("get head COUNT %v, want 2", value)
+	}
+	if value := tail.GetRRule().OrigOptions.Count; value != 4 {
+		t.Errorf("get tail COUNT %v, want 4", value)
+	}
+	if value := tail.GetDTStart(); !value.Equal(time.Date(1997, 11, 2, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("get tail DTSTART %v", value)
+	}
+	if len(head.GetRDate()) != 1 || len(tail.GetExDate()) != 1 || len(head.GetExDate()) != 0 {
+		t.Errorf("RDATE and EXDATE are not partitioned: %v", tail.Recurrence())
+	}
+	// The original set is not modified.
+	if value := set.All(); !timesEqual(value, all) {
+		t.Errorf("get %v, want %v", value, all)
+	}
+}
+
+func TestSplitAtUntil(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: YEARLY, Dtstart: time.Date(1997, 1, 31, 9, 0, 0, 0, time.UTC)})
+	set := Set{}
+	set.RRule(r)
+	head, tail, err := set.SplitAt(time.Date(2000, 1, 31, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := head.GetRRule().GetUntil(); !value.Equal(time.Date(1999, 1, 31, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("get head UNTIL %v", value)
+	}
+	// The inferred BYMONTH and BYMONTHDAY are kept explicitly, and so is the default UNTIL of the original.
+	if value, want := tail.GetRRule().String(), "DTSTART:20000131T090000Z\nRRULE:FREQ=YEARLY;UNTIL=22890512T084716Z;BYMONTH=1;BYMONTHDAY=31"; value != want {
+		t.Errorf("get %q, want %q", value, want)
+	}
+	if value := len(head.All()); value != 3 {
+		t.Errorf("get %v head occurrences, want 3", value)
+	}
+}
+
+func TestSplitAtDefaultEnd(t *testing.T) {
+	// The rule reaches its default end, about 290 years after DTSTART, before COUNT.
+	set, _ := StrToRRuleSet("DTSTART:19970131T090000Z\nRRULE:FREQ=YEARLY;COUNT=400")
+	all := set.All()
+	head, tail, _ := set.SplitAt(time.Date(2000, 1, 31, 9, 0, 0, 0, time.UTC))
+	if value := append(head.All(), tail.All()...); !timesEqual(value, all) {
+		t.Errorf("get %v occurrences, want %v", len(value), len(all))
+	}
+	if value := tail.GetRRule().OrigOptions.Count; value != len(all)-3 {
+		t.Errorf("get tail COUNT %v, want %v", value, len(all)-3)
+	}
+}
+
+func TestSplitAtEveryOccurrence(t *testing.T) {
+	for _, s := range []string{
+		"DTSTART:20240101T090000Z\nRRULE:FREQ=WEEKLY;COUNT=4;BYDAY=MO,FR;BYSETPOS=2",
+		"DTSTART:20240103T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;COUNT=8;BYDAY=MO,WE,FR;BYSETPOS=1,-1;WKST=SU",
+		"DTSTART:20240115T090000Z\nRRULE:FREQ=MONTHLY;COUNT=8;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1,-1",
+		"DTSTART:20240115T090000Z\nRRULE:FREQ=MONTHLY;INTERVAL=2;COUNT=6;BYMONTHDAY=1,15,-1;BYHOUR=9,17;BYSETPOS=2,-2",
+		"DTSTART:20240301T090000Z\nRRULE:FREQ=YEARLY;COUNT=6;BYMONTH=3,9;BYDAY=SU;BYSETPOS=1,-1",
+		"DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY;INTERVAL=3;COUNT=6;BYHOUR=9,12,17;BYSETPOS=-1",
+		"DTSTART:20240101T090000Z\nRRULE:FREQ=HOURLY;INTERVAL=5;COUNT=6;BYMINUTE=0,20,40;BYSETPOS=2",
+		"DTSTART:20240103T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;BYSETPOS=-1;UNTIL=20240401T000000Z",
+	} {
+		set, err := StrToRRuleSet(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		all := set.All()
+		for _, split := range all {
+			head, tail, err := set.SplitAt(split)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value := append(head.All(), tail.All()...); !timesEqual(value, all) {
+				t.Errorf("%s split at %v: get %v, want %v", s, split, value, all)
+			}
+		}
+	}
+}
+
+func TestSplitAtOverride(t *testing.T) {
+	set := overrideSet()
+	set.Override(Override{RecurrenceID: time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC), Start: time.Date(1997, 9, 3, 15, 0, 0, 0, time.UTC)})
+	// The override is split with its original time.
+	head, tail, err := set.SplitAt(time.Date(1997, 9, 3, 15, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(head.GetOverrides()) != 0 || len(tail.GetOverrides()) != 1 {
+		t.Errorf("get %v and %v", head.GetOverrides(), tail.GetOverrides())
+	}
+	if value := len(head.All()) + len(tail.All()); value != 5 {
+		t.Errorf("get %v occurrences, want 5", value)
+	}
+
+	if _, _, err := set.SplitAt(time.Date(1997, 9, 4, 10, 0, 0, 0, time.UTC)); err != ErrNotOccurrence {
+		t.Errorf("get %v, want ErrNotOccurrence", err)
+	}
+}
+
+func TestTruncateAt(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: DAILY, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	set := Set{}
+	set.RRule(r)
+	head, err := set.TruncateAt(time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC))
+	if err != nil || len(head.All()) != 0 {
+		t.Errorf("get %v %v, want no occurrence", head.All(), err)
+	}
+	head, _ = set.TruncateAt(time.Date(1997, 9, 5, 9, 0, 0, 0, time.UTC))
+	if value := len(head.All()); value != 3 {
+		t.Errorf("get %v occurrences, want 3", value)
+	}
+}
+
+func TestDeleteInstance(t *testing.T) {
+	set := overrideSet()
+	set.RDate(time.Date(1997, 9, 10, 9, 0, 0, 0, time.UTC))
+	set.Override(Override{RecurrenceID: time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC), Start: time.Date(1997, 9, 4, 15, 0, 0, 0, time.UTC)})
+
+	result, err := set.DeleteInstance(time.Date(1997, 9, 10, 9, 0, 0, 0, time.UTC))
+	if err != nil || len(result.GetRDate()) != 0 || len(result.GetExDate()) != 0 {
+		t.Errorf("get %v %v, want the RDATE removed", result.Recurrence(), err)
+	}
+	result, _ = result.DeleteInstance(time.Date(1997, 9, 4, 15, 0, 0, 0, time.UTC))
+	want := []time.Time{
+		time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 5, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 6, 9, 0, 0, 0, time.UTC),
+	}
+	if value := result.All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+	if len(result.GetOverrides()) != 0 || len(result.GetExDate()) != 1 {
+		t.Errorf("get %v, want an EXDATE instead of the override", result.Recurrence())
+	}
+	if len(set.All()) != 6 {
+		t.Errorf("the original set was modified: %v", set.All())
+	}
+}
@@ -0,0 +1,199 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Explanation tells whether a time is an occurrence of a recurrence and, if not, why.
+type Explanation struct {
+	// Occurs reports whether the time is an occurrence.
+	Occurs bool
+	// Part is the part of the recurrence that decides, such as "BYMONTHDAY", "UNTIL",
+	// "COUNT" or "EXDATE" when the time is not an occurrence, and "RRULE", "RDATE"
+	// or "RECURRENCE-ID" when it is one.
+	Part string
+	// Inferred reports whether Part was not given but inferred from DTSTART.
+	Inferred bool
+	// Index is the zero-based position of the time in the RRULE, or -1 if it is not one of its occurrences.
+	Index int
+	// Reason describes the explanation in English.
+	Reason string
+}
+
+// explainLayout is the layout of the times in explanations.
+const explainLayout = "Mon Jan 2 2006 15:04:05 MST"
+
+func (e Explanation) String() string {
+	return e.Reason
+}
+
+// Explain tells whether t is an occurrence of the RRule and, if not, which part of the rule
+// rejects it: DTSTART, UNTIL, the time of day (BYHOUR, BYMINUTE, BYSECOND), INTERVAL,
+// the day filters in the order the iterator applies them (BYMONTH, BYWEEKNO, BYDAY,
+// BYEASTER, BYMONTHDAY, BYYEARDAY), BYSETPOS and finally COUNT.
+// Parts inferred from DTSTART, such as the day of the month of a MONTHLY rule without
+// BYxxx parts, are reported as such.
+func (r *RRule) Explain(t time.Time) Explanation {
+	t = t.In(r.dtstart.Location())
+	o, resolved := r.OrigOptions, r.Options
+	reject := func(part string, inferred bool, format string, args ...interface{}) Explanation {
+		reason := fmt.Sprintf(format, args...)
+		if inferred {
+			reason += ", inferred from DTSTART"
+		}
+		return Explanation{Part: part, Inferred: inferred, Index: -1, Reason: reason}
+	}
+	at := t.Format(explainLayout)
+
+	switch {
+	case t.Nanosecond() != 0:
+		return reject("DTSTART", false, "%s is not a whole second like DTSTART", at)
+	case t.Before(r.dtstart):
+		return reject("DTSTART", false, "%s is before DTSTART %s", at, r.dtstart.Format(explainLayout))
+	case t.After(r.until):
+		return reject("UNTIL", o.Until.IsZero(), "%s is after UNTIL %s", at, r.until.Format(explainLayout))
+	}
+	for _, part := range []struct {
+		name     string
+		values   []int
+		value    int
+		inferred bool
+	}{
+		{"BYHOUR", r.byhour, t.Hour(), len(o.Byhour) == 0},
+		{"BYMINUTE", r.byminute, t.Minute(), len(o.Byminute) == 0},
+		{"BYSECOND", r.bysecond, t.Second(), len(o.Bysecond) == 0},
+	} {
+		if len(part.values) != 0 && !contains(part.values, part.value) {
+			return reject(part.name, part.inferred, "%s is not at %s=%s", at, part.name, joinInts(part.values))
+		}
+	}
+
+	unit := strings.TrimSuffix(strings.ToLower(r.freq.String()), "ly")
+	if r.freq == DAILY {
+		unit = "day"
+	}
+	iterator := r.iteratorAt(t)
+	i := -1
+	if iterator != nil {
+		iterator.filterDaySet()
+		i = iterator.dayIndex(t)
+	}
+	if i < 0 {
+		return reject("INTERVAL", false, "the %s of %s is not one of every %d %ss from DTSTART", unit, at, r.interval, unit)
+	}
+	if !iterator.dayset[i-iterator.dayset[0].Int].Defined {
+		switch part := iterator.dayFilter(i); part {
+		case "BYMONTH":
+			return reject(part, len(o.Bymonth) == 0, "%s is not in BYMONTH=%s", t.Month(), joinInts(resolved.Bymonth))
+		case "BYWEEKNO":
+			return reject(part, false, "the week of %s is not in BYWEEKNO=%s", at, joinInts(o.Byweekno))
+		case "BYDAY":
+			days := make([]string, len(resolved.Byweekday))
+			for j, wday := range resolved.Byweekday {
+				days[j] = wday.String()
+			}
+			if len(r.byweekday) != 0 && !contains(r.byweekday, toPyWeekday(t.Weekday())) {
+				return reject(part, len(o.Byweekday) == 0, "%s is not in BYDAY=%s", t.Weekday(), strings.Join(days, ","))
+			}
+			scope := "year"
+			if r.freq == MONTHLY || len(r.bymonth) != 0 {
+				scope = "month"
+			}
+			return reject(part, false, "%s is not one of BYDAY=%s in its %s", at, strings.Join(days, ","), scope)
+		case "BYEASTER":
+			return reject(part, false, "%s is not BYEASTER=%s days from Easter Sunday", at, joinInts(o.Byeaster))
+		case "BYMONTHDAY":
+			e := reject(part, len(o.Bymonthday) == 0, "day %d of %s is not in BYMONTHDAY=%s", t.Day(), t.Month(), joinInts(resolved.Bymonthday))
+			days := daysIn(t.Month(), t.Year())
+			for _, mday := range resolved.Bymonthday {
+				if mday > days {
+					e.Reason += fmt.Sprintf("; %s %d has %d days", t.Month(), t.Year(), days)
+					break
+				}
+			}
+			return e
+		case "BYYEARDAY":
+			return reject(part, false, "day %d of %d is not in BYYEARDAY=%s", t.YearDay(), t.Year(), joinInts(o.Byyearday))
+		}
+	}
+	if len(r.bysetpos) != 0 && !timeContains(iterator.setPositions(), t) {
+		return reject("BYSETPOS", false, "%s is not at BYSETPOS=%s of the occurrences of its %s", at, joinInts(r.bysetpos), unit)
+	}
+	index := r.iterator(context.Background()).countBetween(time.Time{}, t, false)
+	if r.count != 0 && index >= r.count {
+		return reject("COUNT", false, "the recurrence ended after COUNT=%d occurrences, before %s", r.count, at)
+	}
+	return Explanation{Occurs: true, Part: "RRULE", Index: index,
+		Reason: fmt.Sprintf("%s is occurrence %d of the RRULE", at, index+1)}
+}
+
+// Explain tells whether t is an occurrence of the rrule.Set and, if not, why.
+// It reports occurrences moved to or from t by an override, times excluded
+// by an EXDATE and RDATEs, and otherwise the explanation of the RRULE.
+func (set *Set) Explain(t time.Time) Explanation {
+	at := t.Format(explainLayout)
+	applied := set.appliedOverrides()
+	for _, o := range applied {
+		if o.effectiveStart().Equal(t) {
+			return Explanation{Occurs: true, Part: "RECURRENCE-ID", Index: -1,
+				Reason: fmt.Sprintf("%s is the occurrence of %s, modified by an override", at, o.RecurrenceID.Format(explainLayout))}
+		}
+	}
+	if o := overrideOf(applied, t); o != nil {
+		return Explanation{Part: "RECURRENCE-ID", Index: -1,
+			Reason: fmt.Sprintf("%s was moved to %s by an override", at, o.effectiveStart().Format(explainLayout))}
+	}
+	if timeContains(set.exdate, t) {
+		e := Explanation{Part: "EXDATE", Index: -1, Reason: fmt.Sprintf("%s is excluded by an EXDATE", at)}
+		if set.rrule != nil && set.rrule.Contains(t) {
+			e.Reason += ", the RRULE would produce it"
+		}
+		if timeContains(set.rdate, t) {
+			e.Reason += ", it is also an RDATE"
+		}
+		return e
+	}
+	if timeContains(set.rdate, t) {
+		return Explanation{Occurs: true, Part: "RDATE", Index: -1, Reason: fmt.Sprintf("%s is an RDATE", at)}
+	}
+	if set.rrule == nil {
+		return Explanation{Part: "RDATE", Index: -1, Reason: fmt.Sprintf("%s is not an RDATE and the set has no RRULE", at)}
+	}
+	return set.rrule.Explain(t)
+}
+
+// dayFilter returns the name of the first BYxxx part that rejects the day i of the
+// current year, or an empty string if the day passes them all. It mirrors the conditions
+// of filterDaySet, which keeps them inline as they are on the hot path of the iterator.
+func (iterator *rIterator) dayFilter(i int) string {
+	r := iterator.ii.rrule
+	switch {
+	case len(r.bymonth) != 0 && !contains(r.bymonth, iterator.ii.mmask[i]):
+		return "BYMONTH"
+	case len(r.byweekno) != 0 && iterator.ii.wnomask[i] == 0:
+		return "BYWEEKNO"
+	case len(r.byweekday) != 0 && !contains(r.byweekday, iterator.ii.wdaymask[i]),
+		len(iterator.ii.nwdaymask) != 0 && iterator.ii.nwdaymask[i] == 0:
+		return "BYDAY"
+	case len(r.byeaster) != 0 && iterator.ii.eastermask[i] == 0:
+		return "BYEASTER"
+	case (len(r.bymonthday) != 0 || len(r.bynmonthday) != 0) &&
+		!contains(r.bymonthday, iterator.ii.mdaymask[i]) &&
+		!contains(r.bynmonthday, iterator.ii.nmdaymask[i]):
+		return "BYMONTHDAY"
+	case len(r.byyearday) != 0 &&
+		(i < iterator.ii.yearlen &&
+			!contains(r.byyearday, i+1) &&
+			!contains(r.byyearday, -iterator.ii.yearlen+i) ||
+			i >= iterator.ii.yearlen &&
+				!contains(r.byyearday, i+1-iterator.ii.yearlen) &&
+				!contains(r.byyearday, -iterator.ii.nextyearlen+i-iterator.ii.yearlen)):
+		return "BYYEARDAY"
+	}
+	return ""
+}
@@ -0,0 +1,101 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExplain(t *testing.T) {
+	for _, c := range []struct {
+		rule     string
+		t        time.Time
+		part     string
+		inferred bool
+		reason   string
+	}{
+		{"DTSTART:20240131T090000Z\nRRULE:FREQ=MONTHLY", time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC),
+			"BYMONTHDAY", true, "day 29 of February is not in BYMONTHDAY=31, inferred from DTSTART; February 2024 has 29 days"},
+		{"DTSTART:20240131T090000Z\nRRULE:FREQ=MONTHLY", time.Date(2024, 3, 31, 9, 0, 0, 0, time.UTC),
+			"RRULE", false, "Sun Mar 31 2024 09:00:00 UTC is occurrence 2 of the RRULE"},
+		{"DTSTART:20240131T090000Z\nRRULE:FREQ=MONTHLY", time.Date(2024, 3, 31, 10, 0, 0, 0, time.UTC),
+			"BYHOUR", true, "Sun Mar 31 2024 10:00:00 UTC is not at BYHOUR=9, inferred from DTSTART"},
+		{"DTSTART:20240131T090000Z\nRRULE:FREQ=MONTHLY", time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			"DTSTART", false, "Mon Jan 1 2024 09:00:00 UTC is before DTSTART Wed Jan 31 2024 09:00:00 UTC"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY;UNTIL=20240110T000000Z", time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC),
+			"UNTIL", false, "is after UNTIL"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY;COUNT=3", time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC),
+			"COUNT", false, "the recurrence ended after COUNT=3 occurrences"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY;INTERVAL=2", time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC),
+			"INTERVAL", false, "the day of Thu Jan 4 2024 09:00:00 UTC is not one of every 2 days from DTSTART"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,FR", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+			"BYDAY", false, "Tuesday is not in BYDAY=MO,FR"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=WEEKLY", time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+			"BYDAY", true, "Tuesday is not in BYDAY=MO, inferred from DTSTART"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=MONTHLY;BYDAY=1FR", time.Date(2024, 1, 12, 9, 0, 0, 0, time.UTC),
+			"BYDAY", false, "is not one of BYDAY=+1FR in its month"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=YEARLY;BYMONTH=1,2", time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+			"BYMONTH", false, "March is not in BYMONTH=1,2"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=YEARLY;BYWEEKNO=20", time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+			"BYWEEKNO", false, "is not in BYWEEKNO=20"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=YEARLY;BYYEARDAY=1,-1", time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+			"BYYEARDAY", false, "day 61 of 2024 is not in BYYEARDAY=1,-1"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=YEARLY;BYEASTER=0", time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC),
+			"BYEASTER", false, "is not BYEASTER=0 days from Easter Sunday"},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", time.Date(2024, 1, 30, 9, 0, 0, 0, time.UTC),
+			"BYSETPOS", false, "is not at BYSETPOS=-1 of the occurrences of its month"},
+	} {
+		r, err := StrToRRule(c.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e := r.Explain(c.t)
+		if e.Occurs != (c.part == "RRULE") || e.Part != c.part || e.Inferred != c.inferred || !strings.Contains(e.Reason, c.reason) {
+			t.Errorf("%s at %v: get %+v, want %s %v %q", c.rule, c.t, e, c.part, c.inferred, c.reason)
+		}
+	}
+}
+
+func TestExplainAgreesWithContains(t *testing.T) {
+	for _, s := range []string{
+		"DTSTART:19970902T090000Z\nRRULE:FREQ=MONTHLY;COUNT=20;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1,-1",
+		"DTSTART:19970902T090000Z\nRRULE:FREQ=YEARLY;BYWEEKNO=1,20;BYDAY=MO",
+		"DTSTART:19970902T090000Z\nRRULE:FREQ=HOURLY;INTERVAL=5;BYHOUR=0,5,10,15;BYMINUTE=0,30",
+		"DTSTART:19970902T090000Z\nRRULE:FREQ=WEEKLY;INTERVAL=2;UNTIL=19971231T000000Z;BYDAY=TU,SU;WKST=SU",
+	} {
+		r, _ := StrToRRule(s)
+		for d := time.Date(1997, 9, 1, 0, 0, 0, 0, time.UTC); d.Year() == 1997; d = d.Add(30 * time.Minute) {
+			if e := r.Explain(d); e.Occurs != r.Contains(d) || e.Occurs && r.Before(d, false).IsZero() != (e.Index == 0) {
+				t.Fatalf("%s at %v: get %+v, want %v", s, d, e, r.Contains(d))
+			}
+		}
+	}
+}
+
+func TestSetExplain(t *testing.T) {
+	set, _ := StrToRRuleSet("DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY;COUNT=5\nRDATE:20240110T090000Z\nEXDATE:20240102T090000Z")
+	set.Override(Override{RecurrenceID: time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), Start: time.Date(2024, 1, 3, 15, 0, 0, 0, time.UTC)})
+	for _, c := range []struct {
+		t      time.Time
+		occurs bool
+		part   string
+		reason string
+	}{
+		{time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), false, "EXDATE", "is excluded by an EXDATE, the RRULE would produce it"},
+		{time.Date(2024, 1, 10, 9, 0, 0, 0, time.UTC), true, "RDATE", "is an RDATE"},
+		{time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC), false, "RECURRENCE-ID", "was moved to Wed Jan 3 2024 15:00:00 UTC by an override"},
+		{time.Date(2024, 1, 3, 15, 0, 0, 0, time.UTC), true, "RECURRENCE-ID", "modified by an override"},
+		{time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC), true, "RRULE", "occurrence 4 of the RRULE"},
+		{time.Date(2024, 1, 6, 9, 0, 0, 0, time.UTC), false, "COUNT", "COUNT=5"},
+	} {
+		e := set.Explain(c.t)
+		if e.Occurs != c.occurs || e.Part != c.part || !strings.Contains(e.Reason, c.reason) {
+			t.Errorf("%v: get %+v, want %v %s %q", c.t, e, c.occurs, c.part, c.reason)
+		}
+		if e.Occurs != set.Contains(c.t) {
+			t.Errorf("%v: Explain and Contains disagree", c.t)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import "time"
+
+// Floating is the location of floating times (RFC 5545, section 3.3.5), such as
+// DTSTART:20240101T090000 without Z or TZID, which mean the same wall-clock time
+// wherever the user is. It behaves like UTC for computations, so that recurrences
+// of floating times never cross daylight saving time transitions.
+//
+// Pass Floating as the location of StrToROptionInLocation or StrSliceToRRuleSetInLoc
+// to parse times without zone information as floating times, and use InLocation
+// to expand them into a location at query time. Floating times are serialized
+// without zone information, UNTIL included.
+var Floating = time.FixedZone("Floating", 0)
+
+// IsFloating reports whether t is a floating time.
+func IsFloating(t time.Time) bool {
+	return t.Location() == Floating
+}
@@ -0,0 +1,61 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFloatingString(t *testing.T) {
+	option, err := StrToROptionInLocation("DTSTART:20240101T090000\nRRULE:FREQ=DAILY;UNTIL=20240105T090000", Floating)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsFloating(option.Dtstart) || !IsFloating(option.Until) {
+		t.Errorf("get %v and %v, want floating times", option.Dtstart, option.Until)
+	}
+	r, _ := NewRRule(*option)
+	if value, want := r.String(), "DTSTART:20240101T090000\nRRULE:FREQ=DAILY;UNTIL=20240105T090000"; value != want {
+		t.Errorf("get %q, want %q", value, want)
+	}
+	if value := len(r.All()); value != 5 {
+		t.Errorf("get %v occurrences, want 5", value)
+	}
+
+	// Times with Z keep their zone.
+	option, _ = StrToROptionInLocation("DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY", Floating)
+	if IsFloating(option.Dtstart) {
+		t.Errorf("get %v, want UTC", option.Dtstart)
+	}
+}
+
+func TestFloatingSet(t *testing.T) {
+	set, err := StrSliceToRRuleSetInLoc([]string{
+		"DTSTART:20240309T090000",
+		"RRULE:FREQ=DAILY;COUNT=3",
+		"RDATE:20240315T090000Z",
+		"EXDATE:20240310T090000",
+	}, Floating)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, want := set.String(), "DTSTART:20240309T090000\nRRULE:FREQ=DAILY;COUNT=3\nRDATE:20240315T090000Z\nEXDATE:20240310T090000"; value != want {
+		t.Errorf("get %q, want %q", value, want)
+	}
+
+	// Floating times are expanded into the location of the user, others keep their instant.
+	newYork, _ := time.LoadLocation("America/New_York")
+	result, err := set.InLocation(newYork, KeepInstant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		time.Date(2024, 3, 9, 9, 0, 0, 0, newYork),
+		time.Date(2024, 3, 11, 9, 0, 0, 0, newYork),
+		time.Date(2024, 3, 15, 5, 0, 0, 0, newYork),
+	}
+	if value := result.All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
@@ -0,0 +1,209 @@
+// 2017-2022, Teambition. All rights reserved.
+
+// Package freebusy computes busy intervals and free slots from recurring series,
+// given as rrule.Set with a duration.
+package freebusy
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// Interval is the time range [Start, End).
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the interval.
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// WorkingHours are the hours of each day during which an attendee is available.
+// The zero value means always available.
+type WorkingHours struct {
+	// Start and End are the wall-clock times of the day, as durations since midnight,
+	// between which the attendee is available, such as 9*time.Hour and 17*time.Hour.
+	Start time.Duration
+	End   time.Duration
+	// Weekdays are the days the attendee works, every day if empty.
+	Weekdays []time.Weekday
+	// Location is the location of the wall-clock times, UTC if nil.
+	Location *time.Location
+}
+
+// Attendee is someone who is busy during the instances of some series.
+type Attendee struct {
+	Sets  []*rrule.Set
+	Hours WorkingHours
+}
+
+// Busy returns the intervals during which at least one instance of the sets takes place
+// within [after, before), merged and sorted. Instances are those of Set.Instances,
+// so they last the duration of their set or of their override, and instances without
+// duration do not make anyone busy. The instances of each set are listed in order by
+// Set.Instances, the lists are then merged with a heap rather than sorted together.
+func Busy(sets []*rrule.Set, after, before time.Time) []Interval {
+	h := cursors{}
+	for _, set := range sets {
+		if instances := set.Instances(after, before); len(instances) != 0 {
+			h = append(h, instances)
+		}
+	}
+	heap.Init(&h)
+
+	var result []Interval
+	for len(h) != 0 {
+		instance := h[0][0]
+		if h[0] = h[0][1:]; len(h[0]) == 0 {
+			heap.Pop(&h)
+		} else {
+			heap.Fix(&h, 0)
+		}
+		result = appendMerged(result, clip(Interval{instance.Start, instance.End}, after, before))
+	}
+	return result
+}
+
+// FreeSlots returns the intervals within [after, before) during which all the attendees
+// are within their working hours and not busy, that last at least length, sorted.
+func FreeSlots(attendees []Attendee, after, before time.Time, length time.Duration) []Interval {
+	free := []Interval{{after, before}}
+	for _, attendee := range attendees {
+		available := subtract(attendee.Hours.intervals(after, before), Busy(attendee.Sets, after, before))
+		free = intersect(free, available)
+	}
+
+	var result []Interval
+	for _, i := range free {
+		if i.Duration() >= length && i.Duration() > 0 {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// intervals returns the working intervals within [after, before).
+func (w WorkingHours) intervals(after, before time.Time) []Interval {
+	if w.Start == 0 && w.End == 0 {
+		return []Interval{{after, before}}
+	}
+	loc := w.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var result []Interval
+	year, month, day := after.In(loc).Date()
+	// Start the day before, for working hours that end after midnight.
+	for d := day - 1; ; d++ {
+		// Wall-clock times are normalized by time.Date, across DST transitions too.
+		start := time.Date(year, month, d, 0, 0, int(w.Start/time.Second), 0, loc)
+		end := time.Date(year, month, d, 0, 0, int(w.End/time.Second), 0, loc)
+		if !start.Before(before) {
+			return result
+		}
+		if w.works(time.Date(year, month, d, 0, 0, 0, 0, loc).Weekday()) {
+			result = appendMerged(result, clip(Interval{start, end}, after, before))
+		}
+	}
+}
+
+func (w WorkingHours) works(weekday time.Weekday) bool {
+	if len(w.Weekdays) == 0 {
+		return true
+	}
+	for _, d := range w.Weekdays {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// cursors is a min-heap of the remaining instances of each set, ordered by start.
+type cursors [][]rrule.Instance
+
+func (c cursors) Len() int           { return len(c) }
+func (c cursors) Less(i, j int) bool { return c[i][0].Start.Before(c[j][0].Start) }
+func (c cursors) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+
+func (c *cursors) Push(x interface{}) { *c = append(*c, x.([]rrule.Instance)) }
+
+func (c *cursors) Pop() interface{} {
+	old := *c
+	item := old[len(old)-1]
+	*c = old[:len(old)-1]
+	return item
+}
+
+// clip returns the part of i within [after, before).
+func clip(i Interval, after, before time.Time) Interval {
+	if i.Start.Before(after) {
+		i.Start = after
+	}
+	if i.End.After(before) {
+		i.End = before
+	}
+	return i
+}
+
+// appendMerged appends i to the sorted intervals, merging it with the last one
+// if they overlap or touch. Empty intervals are dropped.
+func appendMerged(intervals []Interval, i Interval) []Interval {
+	if !i.End.After(i.Start) {
+		return intervals
+	}
+	if n := len(intervals); n != 0 && !i.Start.After(intervals[n-1].End) {
+		if i.End.After(intervals[n-1].End) {
+			intervals[n-1].End = i.End
+		}
+		return intervals
+	}
+	return append(intervals, i)
+}
+
+// subtract returns the parts of the sorted intervals a not covered by the sorted intervals b.
+func subtract(a, b []Interval) []Interval {
+	var result []Interval
+	for _, i := range a {
+		for len(b) != 0 && !b[0].End.After(i.Start) {
+			b = b[1:]
+		}
+		for _, j := range b {
+			if !j.Start.Before(i.End) {
+				break
+			}
+			result = appendMerged(result, Interval{i.Start, j.Start})
+			if j.End.After(i.Start) {
+				i.Start = j.End
+			}
+		}
+		result = appendMerged(result, i)
+	}
+	return result
+}
+
+// intersect returns the parts covered by both the sorted intervals a and b.
+func intersect(a, b []Interval) []Interval {
+	var result []Interval
+	for len(a) != 0 && len(b) != 0 {
+		start, end := a[0].Start, a[0].End
+		if b[0].Start.After(start) {
+			start = b[0].Start
+		}
+		if b[0].End.Before(end) {
+			end = b[0].End
+		}
+		result = appendMerged(result, Interval{start, end})
+		if a[0].End.Before(b[0].End) {
+			a = a[1:]
+		} else {
+			b = b[1:]
+		}
+	}
+	return result
+}
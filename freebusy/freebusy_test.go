@@ -0,0 +1,102 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package freebusy
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+func series(t *testing.T, s string, d time.Duration) *rrule.Set {
+	set, err := rrule.StrToRRuleSet(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	set.Duration(d)
+	return set
+}
+
+func intervalsEqual(value, want []Interval) bool {
+	if len(value) != len(want) {
+		return false
+	}
+	for i := range value {
+		if !value[i].Start.Equal(want[i].Start) || !value[i].End.Equal(want[i].End) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBusy(t *testing.T) {
+	standup := series(t, "DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY", 30*time.Minute)
+	review := series(t, "DTSTART:20240101T091500Z\nRRULE:FREQ=WEEKLY", time.Hour)
+	lunch := series(t, "DTSTART:20240101T120000Z\nRRULE:FREQ=DAILY", time.Hour)
+	after := time.Date(2024, 1, 1, 9, 10, 0, 0, time.UTC)
+	before := time.Date(2024, 1, 2, 9, 15, 0, 0, time.UTC)
+	value := Busy([]*rrule.Set{standup, review, lunch}, after, before)
+	want := []Interval{
+		{after, time.Date(2024, 1, 1, 10, 15, 0, 0, time.UTC)},
+		{time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 13, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC), before},
+	}
+	if !intervalsEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
+
+func TestFreeSlots(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	london, _ := time.LoadLocation("Europe/London")
+	alice := Attendee{
+		Sets:  []*rrule.Set{series(t, "DTSTART;TZID=America/New_York:20240304T090000\nRRULE:FREQ=DAILY", time.Hour)},
+		Hours: WorkingHours{Start: 9 * time.Hour, End: 17 * time.Hour, Location: newYork},
+	}
+	bob := Attendee{
+		Sets: []*rrule.Set{series(t, "DTSTART;TZID=Europe/London:20240304T150000\nRRULE:FREQ=DAILY", 30*time.Minute)},
+		Hours: WorkingHours{Start: 9 * time.Hour, End: 17 * time.Hour, Location: london,
+			Weekdays: []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}},
+	}
+
+	// New York switches to daylight saving time on 2024-03-10, London on 2024-03-31.
+	for _, c := range []struct {
+		day  time.Time
+		want []Interval
+	}{
+		{time.Date(2024, 3, 4, 0, 0, 0, 0, time.UTC), []Interval{
+			{time.Date(2024, 3, 4, 15, 30, 0, 0, time.UTC), time.Date(2024, 3, 4, 17, 0, 0, 0, time.UTC)},
+		}},
+		{time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), []Interval{
+			{time.Date(2024, 3, 11, 14, 0, 0, 0, time.UTC), time.Date(2024, 3, 11, 15, 0, 0, 0, time.UTC)},
+			{time.Date(2024, 3, 11, 15, 30, 0, 0, time.UTC), time.Date(2024, 3, 11, 17, 0, 0, 0, time.UTC)},
+		}},
+		// Bob does not work on Saturdays.
+		{time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC), nil},
+	} {
+		value := FreeSlots([]Attendee{alice, bob}, c.day, c.day.AddDate(0, 0, 1), time.Hour)
+		if !intervalsEqual(value, c.want) {
+			t.Errorf("%v: get %v, want %v", c.day, value, c.want)
+		}
+	}
+
+	// Slots shorter than the requested length are dropped.
+	day := time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC)
+	if value := FreeSlots([]Attendee{alice, bob}, day, day.AddDate(0, 0, 1), 90*time.Minute); len(value) != 1 {
+		t.Errorf("get %v, want one slot", value)
+	}
+}
+
+func TestIntervalOperations(t *testing.T) {
+	at := func(h int) time.Time { return time.Date(2024, 1, 1, h, 0, 0, 0, time.UTC) }
+	a := []Interval{{at(1), at(5)}, {at(7), at(9)}}
+	b := []Interval{{at(2), at(3)}, {at(4), at(8)}}
+	if value, want := subtract(a, b), []Interval{{at(1), at(2)}, {at(3), at(4)}, {at(8), at(9)}}; !reflect.DeepEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+	if value, want := intersect(a, b), []Interval{{at(2), at(3)}, {at(4), at(5)}, {at(7), at(8)}}; !reflect.DeepEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
@@ -0,0 +1,65 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"time"
+)
+
+// Nth returns the occurrence of the RRule at the zero-based position n,
+// and false if the recurrence has fewer occurrences.
+// Whole periods before the occurrence are skipped without being generated.
+func (r *RRule) Nth(n int) (time.Time, bool) {
+	if n < 0 || r.count != 0 && n >= r.count {
+		return time.Time{}, false
+	}
+	iterator := r.iterator(context.Background())
+	iterator.skip(n)
+	return iterator.next()
+}
+
+// IndexOf returns the zero-based position of t in the occurrences of the RRule,
+// and false if t is not an occurrence. It is the inverse of Nth.
+func (r *RRule) IndexOf(t time.Time) (int, bool) {
+	if !r.selects(t) {
+		return -1, false
+	}
+	i := r.iterator(context.Background()).countBetween(time.Time{}, t, false)
+	if r.count != 0 && i >= r.count {
+		return -1, false
+	}
+	return i, true
+}
+
+// Nth returns the occurrence of the rrule.Set at the zero-based position n,
+// and false if the set has fewer occurrences.
+func (set *Set) Nth(n int) (time.Time, bool) {
+	if n < 0 {
+		return time.Time{}, false
+	}
+	if set.onlyRRule() {
+		return set.rrule.Nth(n)
+	}
+	next := set.Iterator()
+	for ; n > 0; n-- {
+		if _, ok := next(); !ok {
+			return time.Time{}, false
+		}
+	}
+	return next()
+}
+
+// IndexOf returns the zero-based position of t in the occurrences of the rrule.Set,
+// and false if t is not an occurrence. It is the inverse of Nth.
+func (set *Set) IndexOf(t time.Time) (int, bool) {
+	if !set.Contains(t) {
+		return -1, false
+	}
+	return set.CountBetween(time.Time{}, t, false), true
+}
+
+// onlyRRule reports whether the occurrences of the set are exactly those of its RRULE.
+func (set *Set) onlyRRule() bool {
+	return set.rrule != nil && len(set.rdate) == 0 && len(set.exdate) == 0 && len(set.overrides) == 0
+}
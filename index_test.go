@@ -0,0 +1,83 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNthAndIndexOf(t *testing.T) {
+	dtstart := time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)
+	until := time.Date(2000, 3, 1, 9, 0, 0, 0, time.UTC)
+	for _, option := range []ROption{
+		{Freq: YEARLY, Count: 10, Byyearday: []int{1, 100, 200, -1}},
+		{Freq: MONTHLY, Count: 25, Byweekday: []Weekday{MO, TU, WE, TH, FR}, Bysetpos: []int{-1}},
+		{Freq: MONTHLY, Until: until, Bymonthday: []int{1, 15, -1}},
+		{Freq: WEEKLY, Count: 17, Interval: 2, Byweekday: []Weekday{TU, TH}},
+		{Freq: DAILY, Until: until, Bymonth: []int{2, 4}, Byhour: []int{9, 21}},
+		{Freq: HOURLY, Count: 100, Byhour: []int{9, 10, 11}, Byminute: []int{0, 30}},
+	} {
+		option.Dtstart = dtstart
+		r, _ := NewRRule(option)
+		all := r.All()
+		for i, want := range all {
+			if v, ok := r.Nth(i); !ok || v != want {
+				t.Errorf("%s: Nth(%d) get %v %v, want %v", r, i, v, ok, want)
+			}
+			if n, ok := r.IndexOf(want); !ok || n != i {
+				t.Errorf("%s: IndexOf(%v) get %d %v, want %d", r, want, n, ok, i)
+			}
+		}
+		if v, ok := r.Nth(len(all)); ok {
+			t.Errorf("%s: Nth(%d) get %v, want none", r, len(all), v)
+		}
+		if n, ok := r.IndexOf(all[0].Add(time.Second)); ok {
+			t.Errorf("%s: IndexOf get %d, want none", r, n)
+		}
+	}
+}
+
+func TestNthInfinite(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: WEEKLY, Byweekday: []Weekday{MO, WE, FR},
+		Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)})
+	// 2024 has 52 weeks and a Monday, December 30th.
+	v, ok := r.Nth(157)
+	want := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	if !ok || v != want {
+		t.Errorf("get %v %v, want %v", v, ok, want)
+	}
+	if n, ok := r.IndexOf(want); !ok || n != 157 {
+		t.Errorf("get %d %v, want 157", n, ok)
+	}
+	if _, ok := r.Nth(-1); ok {
+		t.Error("Nth(-1) should not exist")
+	}
+}
+
+func TestSetNthAndIndexOf(t *testing.T) {
+	set := Set{}
+	r, _ := NewRRule(ROption{Freq: WEEKLY, Count: 4,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	set.RRule(r)
+	if v, ok := set.Nth(3); !ok || v != time.Date(1997, 9, 23, 9, 0, 0, 0, time.UTC) {
+		t.Errorf("get %v %v", v, ok)
+	}
+	set.RDate(time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC))
+	set.ExDate(time.Date(1997, 9, 16, 9, 0, 0, 0, time.UTC))
+	all := set.All()
+	for i, want := range all {
+		if v, ok := set.Nth(i); !ok || v != want {
+			t.Errorf("Nth(%d) get %v %v, want %v", i, v, ok, want)
+		}
+		if n, ok := set.IndexOf(want); !ok || n != i {
+			t.Errorf("IndexOf(%v) get %d %v, want %d", want, n, ok, i)
+		}
+	}
+	if _, ok := set.Nth(len(all)); ok {
+		t.Errorf("Nth(%d) should not exist", len(all))
+	}
+	if _, ok := set.IndexOf(time.Date(1997, 9, 16, 9, 0, 0, 0, time.UTC)); ok {
+		t.Error("excluded date should have no index")
+	}
+}
@@ -0,0 +1,102 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"time"
+)
+
+// Instance is an occurrence of a recurrence together with its end,
+// like an event with DTSTART and DTEND or DURATION.
+type Instance struct {
+	Start time.Time
+	End   time.Time
+	// RecurrenceID is the original time of the occurrence, Start unless it is overridden.
+	RecurrenceID time.Time
+	// Override is the override applied to the occurrence, if any.
+	Override *Override
+}
+
+// Duration sets the duration of every occurrence of the rule, like the DURATION of an event.
+// Negative durations are ignored.
+func (r *RRule) Duration(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	r.duration = d
+}
+
+// GetDuration gets the duration of the occurrences of the rule
+func (r *RRule) GetDuration() time.Duration {
+	return r.duration
+}
+
+// Instances returns the instances of the RRule that overlap the time range [after, before),
+// following the time-range semantics of CalDAV (RFC 4791, section 9.9):
+// an instance with a duration overlaps when it starts before before and ends after after,
+// an instance without duration when it starts within the range.
+// Unlike Between, it includes instances that started before after and are still running.
+func (r *RRule) Instances(after, before time.Time) []Instance {
+	// Occurrences ending before the range are skipped period by period.
+	n := r.iterator(context.Background()).countBetween(time.Time{}, after.Add(-r.duration), r.duration != 0)
+	iterator := r.iterator(context.Background())
+	iterator.skip(n)
+	return instances(func() (Occurrence, bool) {
+		v, ok := iterator.next()
+		return Occurrence{Time: v, RecurrenceID: v}, ok
+	}, r.duration, after, before)
+}
+
+// Duration sets the duration of every occurrence of the set, like the DURATION of an event.
+// Negative durations are ignored.
+func (set *Set) Duration(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	set.duration = d
+}
+
+// GetDuration gets the duration of the occurrences of the set
+func (set *Set) GetDuration() time.Duration {
+	return set.duration
+}
+
+// Instances returns the instances of the rrule.Set that overlap the time range [after, before),
+// following the time-range semantics of CalDAV (RFC 4791, section 9.9).
+// See RRule.Instances. Overridden occurrences are returned at their effective time,
+// with the duration of the override if it has one.
+func (set *Set) Instances(after, before time.Time) []Instance {
+	occurrences, _ := set.occurrences(context.Background())
+	return instances(occurrences, set.duration, after, before)
+}
+
+// overlaps reports whether the instance overlaps the time range [after, before).
+func (i Instance) overlaps(after, before time.Time) bool {
+	if i.End.Equal(i.Start) {
+		return !i.Start.Before(after) && i.Start.Before(before)
+	}
+	return i.Start.Before(before) && i.End.After(after)
+}
+
+func instances(next func() (Occurrence, bool), d time.Duration, after, before time.Time) []Instance {
+	result := []Instance{}
+	for {
+		o, ok := next()
+		if !ok || !o.Time.Before(before) {
+			return result
+		}
+		if i := newInstance(o, d); i.overlaps(after, before) {
+			result = append(result, i)
+		}
+	}
+}
+
+// newInstance returns the instance of the occurrence lasting d, or the duration of its override.
+func newInstance(o Occurrence, d time.Duration) Instance {
+	i := Instance{Start: o.Time, End: o.Time.Add(d), RecurrenceID: o.RecurrenceID, Override: o.Override}
+	if o.Override != nil && o.Override.Duration > 0 {
+		i.End = o.Time.Add(o.Override.Duration)
+	}
+	return i
+}
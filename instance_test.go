@@ -0,0 +1,93 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+// instance returns the Instance of an occurrence that is not overridden.
+func instance(start, end time.Time) Instance {
+	return Instance{Start: start, End: end, RecurrenceID: start}
+}
+
+func instancesEqual(value, want []Instance) bool {
+	if len(value) != len(want) {
+		return false
+	}
+	for i := range value {
+		if value[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestInstances(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: DAILY, Count: 5,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	r.Duration(2 * time.Hour)
+	// The occurrence of the 3rd is still running at 10:00.
+	value := r.Instances(time.Date(1997, 9, 3, 10, 0, 0, 0, time.UTC), time.Date(1997, 9, 5, 9, 0, 0, 0, time.UTC))
+	want := []Instance{
+		instance(time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC), time.Date(1997, 9, 3, 11, 0, 0, 0, time.UTC)),
+		instance(time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC), time.Date(1997, 9, 4, 11, 0, 0, 0, time.UTC)),
+	}
+	if !instancesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+
+	// Instances ending exactly at after do not overlap.
+	value = r.Instances(time.Date(1997, 9, 3, 11, 0, 0, 0, time.UTC), time.Date(1997, 9, 4, 9, 0, 1, 0, time.UTC))
+	if !instancesEqual(value, want[1:]) {
+		t.Errorf("get %v, want %v", value, want[1:])
+	}
+}
+
+func TestInstancesWithoutDuration(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: DAILY, Count: 5,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	value := r.Instances(time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC), time.Date(1997, 9, 5, 9, 0, 0, 0, time.UTC))
+	want := []Instance{
+		instance(time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC), time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC)),
+		instance(time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC), time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC)),
+	}
+	if !instancesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
+
+func TestSetInstances(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: WEEKLY, Count: 4,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	r.Duration(24 * time.Hour)
+	set := Set{}
+	set.RRule(r)
+	set.RDate(time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC))
+	if set.GetDuration() != 24*time.Hour {
+		t.Fatalf("get %v, want the duration of the rule", set.GetDuration())
+	}
+	value := set.Instances(time.Date(1997, 9, 8, 0, 0, 0, 0, time.UTC), time.Date(1997, 9, 9, 9, 0, 0, 0, time.UTC))
+	want := []Instance{
+		instance(time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC), time.Date(1997, 9, 8, 9, 0, 0, 0, time.UTC)),
+	}
+	if !instancesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+
+	set.Duration(time.Hour)
+	value = set.Instances(time.Date(1997, 9, 8, 0, 0, 0, 0, time.UTC), time.Date(1997, 9, 9, 9, 0, 0, 0, time.UTC))
+	if len(value) != 0 {
+		t.Errorf("get %v, want none", value)
+	}
+}
+
+func TestDurationKeptOnUntil(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: DAILY, Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	r.Duration(time.Hour)
+	r.Until(time.Date(1997, 9, 10, 9, 0, 0, 0, time.UTC))
+	if r.GetDuration() != time.Hour {
+		t.Errorf("get %v, want %v", r.GetDuration(), time.Hour)
+	}
+}
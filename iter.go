@@ -0,0 +1,95 @@
+// 2017-2022, Teambition. All rights reserved.
+
+//go:build go1.23
+
+package rrule
+
+import (
+	"iter"
+	"time"
+)
+
+// Values returns an iterator over all occurrences of the RRule,
+// for use with range-over-func:
+//
+//	for t := range r.Values() {
+//		...
+//	}
+//
+// Occurrences are generated lazily, so breaking out of the loop stops the expansion.
+func (r *RRule) Values() iter.Seq[time.Time] {
+	return seq(r.Iterator)
+}
+
+// Indexed returns an iterator over all occurrences of the RRule
+// paired with their zero-based position in the recurrence.
+func (r *RRule) Indexed() iter.Seq2[int, time.Time] {
+	return seq2(r.Iterator)
+}
+
+// Occurrences returns an iterator over the occurrences of the RRule between after and before.
+// The inc keyword has the same meaning as in Between.
+func (r *RRule) Occurrences(after, before time.Time, inc bool) iter.Seq[time.Time] {
+	return seqBetween(r.Iterator, after, before, inc)
+}
+
+// Values returns an iterator over all occurrences of the rrule.Set.
+// Occurrences are generated lazily, so breaking out of the loop stops the expansion.
+func (set *Set) Values() iter.Seq[time.Time] {
+	return seq(func() Next { return set.Iterator() })
+}
+
+// Indexed returns an iterator over all occurrences of the rrule.Set
+// paired with their zero-based position in the set.
+func (set *Set) Indexed() iter.Seq2[int, time.Time] {
+	return seq2(func() Next { return set.Iterator() })
+}
+
+// Occurrences returns an iterator over the occurrences of the rrule.Set between after and before.
+// The inc keyword has the same meaning as in Between.
+func (set *Set) Occurrences(after, before time.Time, inc bool) iter.Seq[time.Time] {
+	return seqBetween(func() Next { return set.Iterator() }, after, before, inc)
+}
+
+// seq adapts a Next to iter.Seq. Each range over the result
+// starts a fresh iterator, so the sequence can be reused.
+func seq(iterator func() Next) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		next := iterator()
+		for {
+			v, ok := next()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+func seq2(iterator func() Next) iter.Seq2[int, time.Time] {
+	return func(yield func(int, time.Time) bool) {
+		next := iterator()
+		for i := 0; ; i++ {
+			v, ok := next()
+			if !ok || !yield(i, v) {
+				return
+			}
+		}
+	}
+}
+
+func seqBetween(iterator func() Next, after, before time.Time, inc bool) iter.Seq[time.Time] {
+	return func(yield func(time.Time) bool) {
+		next := iterator()
+		for {
+			v, ok := next()
+			if !ok || inc && v.After(before) || !inc && !v.Before(before) {
+				return
+			}
+			if inc && !v.Before(after) || !inc && v.After(after) {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,104 @@
+// 2017-2022, Teambition. All rights reserved.
+
+//go:build go1.23
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRRuleValues(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: DAILY, Count: 5,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	var value []time.Time
+	for v := range r.Values() {
+		value = append(value, v)
+	}
+	if want := r.All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+
+	// The sequence restarts on every range.
+	value = value[:0]
+	for v := range r.Values() {
+		value = append(value, v)
+		if len(value) == 2 {
+			break
+		}
+	}
+	want := []time.Time{time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC)}
+	if !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
+
+func TestRRuleIndexed(t *testing.T) {
+	r, _ := NewRRule(ROption{Freq: WEEKLY, Count: 3,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	all := r.All()
+	n := 0
+	for i, v := range r.Indexed() {
+		if i != n || v != all[i] {
+			t.Errorf("get %d %v, want %d %v", i, v, n, all[n])
+		}
+		n++
+	}
+	if n != len(all) {
+		t.Errorf("get %d occurrences, want %d", n, len(all))
+	}
+}
+
+func TestRRuleOccurrences(t *testing.T) {
+	// Infinite rule, the window must stop the expansion.
+	r, _ := NewRRule(ROption{Freq: DAILY,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	after := time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC)
+	before := time.Date(1997, 9, 6, 9, 0, 0, 0, time.UTC)
+	for _, inc := range []bool{true, false} {
+		var value []time.Time
+		for v := range r.Occurrences(after, before, inc) {
+			value = append(value, v)
+		}
+		if want := r.Between(after, before, inc); !timesEqual(value, want) {
+			t.Errorf("inc %v: get %v, want %v", inc, value, want)
+		}
+	}
+}
+
+func TestSetValues(t *testing.T) {
+	set := Set{}
+	r, _ := NewRRule(ROption{Freq: WEEKLY, Count: 4,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	set.RRule(r)
+	set.RDate(time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC))
+	set.ExDate(time.Date(1997, 9, 16, 9, 0, 0, 0, time.UTC))
+
+	var value []time.Time
+	for v := range set.Values() {
+		value = append(value, v)
+	}
+	if want := set.All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+
+	var indexes []int
+	for i := range set.Indexed() {
+		indexes = append(indexes, i)
+	}
+	if len(indexes) != 4 || indexes[3] != 3 {
+		t.Errorf("get %v, want [0 1 2 3]", indexes)
+	}
+
+	after := time.Date(1997, 9, 7, 9, 0, 0, 0, time.UTC)
+	before := time.Date(1997, 9, 23, 9, 0, 0, 0, time.UTC)
+	value = value[:0]
+	for v := range set.Occurrences(after, before, true) {
+		value = append(value, v)
+	}
+	if want := set.Between(after, before, true); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
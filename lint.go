@@ -0,0 +1,139 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"fmt"
+	"time"
+)
+
+// Severity tells how serious an Issue found by Lint is.
+type Severity int
+
+const (
+	// Warning marks rules that are valid but likely not what was meant.
+	Warning Severity = iota
+	// Error marks rules that RFC 5545 forbids. They are still expanded,
+	// the way python-dateutil does, unless NewRRule is given WithStrict.
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Issue is a problem found by Lint, with the rule part at fault and
+// the section of RFC 5545 stating the requirement.
+type Issue struct {
+	Severity Severity
+	Part     string
+	Section  string
+	Message  string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%v: %s (RFC 5545, section %s)", i.Severity, i.Message, i.Section)
+}
+
+// Lint checks the options of a rule against the requirements of RFC 5545
+// that NewRRule does not enforce, such as BYWEEKNO with a FREQ other than YEARLY,
+// or COUNT and UNTIL in the same rule. The range checks of NewRRule are not repeated.
+// Checks involving DTSTART are skipped when arg.Dtstart is zero.
+func Lint(arg ROption) []Issue {
+	var issues []Issue
+	report := func(severity Severity, part, section, format string, a ...interface{}) {
+		issues = append(issues, Issue{severity, part, section, fmt.Sprintf(format, a...)})
+	}
+
+	if arg.Count != 0 && !arg.Until.IsZero() {
+		report(Error, "UNTIL", "3.3.10", "COUNT and UNTIL must not occur in the same rule")
+	}
+	if len(arg.Byweekno) != 0 && arg.Freq != YEARLY {
+		report(Error, "BYWEEKNO", "3.3.10", "BYWEEKNO is only valid with FREQ=YEARLY, not FREQ=%v", arg.Freq)
+	}
+	if len(arg.Byyearday) != 0 && (arg.Freq == MONTHLY || arg.Freq == WEEKLY || arg.Freq == DAILY) {
+		report(Error, "BYYEARDAY", "3.3.10", "BYYEARDAY must not be used with FREQ=%v", arg.Freq)
+	}
+	if len(arg.Bymonthday) != 0 && arg.Freq == WEEKLY {
+		report(Error, "BYMONTHDAY", "3.3.10", "BYMONTHDAY must not be used with FREQ=WEEKLY")
+	}
+	for _, wday := range arg.Byweekday {
+		if wday.n == 0 {
+			continue
+		}
+		if arg.Freq != MONTHLY && arg.Freq != YEARLY {
+			report(Error, "BYDAY", "3.3.10", "BYDAY=%v has a numeric value, which is only valid with FREQ=MONTHLY or FREQ=YEARLY", wday)
+		} else if arg.Freq == YEARLY && len(arg.Byweekno) != 0 {
+			report(Error, "BYDAY", "3.3.10", "BYDAY=%v has a numeric value, which is not valid with BYWEEKNO", wday)
+		}
+		break
+	}
+	if len(arg.Bysetpos) != 0 && len(arg.Bymonth)+len(arg.Bymonthday)+len(arg.Byyearday)+len(arg.Byweekno)+
+		len(arg.Byweekday)+len(arg.Byhour)+len(arg.Byminute)+len(arg.Bysecond)+len(arg.Byeaster) == 0 {
+		report(Error, "BYSETPOS", "3.3.10", "BYSETPOS must be used with another BYxxx part")
+	}
+	if len(arg.Byeaster) != 0 {
+		report(Warning, "BYEASTER", "3.3.10", "BYEASTER is an extension, other implementations ignore or reject it")
+	}
+
+	if arg.Dtstart.IsZero() {
+		return issues
+	}
+	if !arg.Until.IsZero() {
+		switch {
+		case IsFloating(arg.Dtstart) && !IsFloating(arg.Until):
+			report(Error, "UNTIL", "3.3.10", "UNTIL must be a floating time, like DTSTART")
+		case !IsFloating(arg.Dtstart) && IsFloating(arg.Until):
+			report(Error, "UNTIL", "3.3.10", "UNTIL must not be a floating time, DTSTART is not")
+		}
+		if arg.Until.Before(arg.Dtstart.Truncate(time.Second)) {
+			report(Warning, "UNTIL", "3.3.10", "UNTIL is before DTSTART, the rule has no occurrences")
+			return issues
+		}
+	}
+	if validateBounds(arg) == nil {
+		if r := buildRRule(arg, systemClock{}); !r.selects(r.dtstart) {
+			report(Warning, "DTSTART", "3.8.5.3", "DTSTART %s does not match the rule and is not an occurrence",
+				r.dtstart.Format(explainLayout))
+		}
+	}
+	return issues
+}
+
+// Lint checks the RRULE of the set with Lint, using the DTSTART of the set,
+// and the RDATE and EXDATE values against DTSTART.
+// EXDATE values that exclude no occurrence are reported as warnings.
+func (set *Set) Lint() []Issue {
+	var issues []Issue
+	if set.rrule != nil {
+		arg := set.rrule.OrigOptions
+		if arg.Dtstart.IsZero() {
+			arg.Dtstart = set.dtstart
+		}
+		issues = Lint(arg)
+	}
+	if set.dtstart.IsZero() {
+		return issues
+	}
+
+	floating := func(name, section string, times []time.Time) {
+		for _, t := range times {
+			if IsFloating(t) != IsFloating(set.dtstart) {
+				issues = append(issues, Issue{Warning, name, section,
+					fmt.Sprintf("%s %s and DTSTART are not both floating times", name, t.Format(explainLayout))})
+			}
+		}
+	}
+	floating("RDATE", "3.8.5.2", set.rdate)
+	floating("EXDATE", "3.8.5.1", set.exdate)
+	for _, t := range set.exdate {
+		if !timeContains(set.rdate, t) && (set.rrule == nil || !set.rrule.Contains(t)) {
+			issues = append(issues, Issue{Warning, "EXDATE", "3.8.5.1",
+				fmt.Sprintf("EXDATE %s excludes no occurrence", t.Format(explainLayout))})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,108 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLint(t *testing.T) {
+	for _, c := range []struct {
+		rule string
+		want []string
+	}{
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO,FR", nil},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY;COUNT=3;UNTIL=20240110T000000Z",
+			[]string{"error: COUNT and UNTIL must not occur in the same rule (RFC 5545, section 3.3.10)"}},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=MONTHLY;BYWEEKNO=1",
+			[]string{"error: BYWEEKNO is only valid with FREQ=YEARLY, not FREQ=MONTHLY"}},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=1MO",
+			[]string{"error: BYDAY=+1MO has a numeric value, which is only valid with FREQ=MONTHLY or FREQ=YEARLY"}},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=YEARLY;BYWEEKNO=1;BYDAY=-1MO",
+			[]string{"error: BYDAY=-1MO has a numeric value, which is not valid with BYWEEKNO", "warning: DTSTART"}},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=WEEKLY;BYMONTHDAY=1",
+			[]string{"error: BYMONTHDAY must not be used with FREQ=WEEKLY"}},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY;BYYEARDAY=1",
+			[]string{"error: BYYEARDAY must not be used with FREQ=DAILY"}},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY;BYSETPOS=1",
+			[]string{"error: BYSETPOS must be used with another BYxxx part"}},
+		{"DTSTART:20240101T090000Z\nRRULE:FREQ=YEARLY;BYEASTER=0",
+			[]string{"warning: BYEASTER is an extension", "warning: DTSTART Mon Jan 1 2024 09:00:00 UTC does not match the rule and is not an occurrence (RFC 5545, section 3.8.5.3)"}},
+		{"DTSTART:20240110T090000Z\nRRULE:FREQ=DAILY;UNTIL=20240101T000000Z",
+			[]string{"warning: UNTIL is before DTSTART"}},
+	} {
+		option, err := StrToROption(c.rule)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issues := Lint(*option)
+		if len(issues) != len(c.want) {
+			t.Errorf("%s: get %v, want %v", c.rule, issues, c.want)
+			continue
+		}
+		for i, issue := range issues {
+			if !strings.HasPrefix(issue.String(), c.want[i]) {
+				t.Errorf("%s: get %v, want %v", c.rule, issue, c.want[i])
+			}
+		}
+	}
+}
+
+func TestLintUntilType(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	zoned := ROption{Freq: DAILY, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, newYork), Until: time.Date(2024, 1, 10, 0, 0, 0, 0, newYork)}
+	// UNTIL is written in UTC whatever its location.
+	if issues := Lint(zoned); len(issues) != 0 {
+		t.Errorf("get %v", issues)
+	}
+
+	option := ROption{Freq: DAILY, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, Floating), Until: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)}
+	if issues := Lint(option); len(issues) != 1 || issues[0].Part != "UNTIL" || issues[0].Severity != Error {
+		t.Errorf("get %v", issues)
+	}
+	option.Dtstart, option.Until = option.Until, option.Dtstart
+	if issues := Lint(option); len(issues) != 2 || issues[0].Message != "UNTIL must not be a floating time, DTSTART is not" {
+		t.Errorf("get %v", issues)
+	}
+}
+
+func TestWithStrict(t *testing.T) {
+	option := ROption{Freq: WEEKLY, Count: 2, Byweekday: []Weekday{MO.Nth(1)}, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)}
+	if _, err := NewRRule(option); err != nil {
+		t.Fatal(err)
+	}
+	_, err := NewRRule(option, WithStrict())
+	if err == nil || !strings.Contains(err.Error(), "BYDAY=+1MO") {
+		t.Errorf("get %v", err)
+	}
+
+	// Warnings are not rejected.
+	if _, err := StrToRRule("DTSTART:20240102T090000Z\nRRULE:FREQ=WEEKLY;BYDAY=MO", WithStrict()); err != nil {
+		t.Error(err)
+	}
+	if _, err := StrToRRuleSet("DTSTART:20240102T090000Z\nRRULE:FREQ=DAILY;COUNT=3;UNTIL=20240110T000000Z", WithStrict()); err == nil ||
+		!strings.Contains(err.Error(), "COUNT and UNTIL") {
+		t.Errorf("get %v", err)
+	}
+}
+
+func TestSetLint(t *testing.T) {
+	set, _ := StrToRRuleSet("DTSTART:20240101T090000Z\nRRULE:FREQ=DAILY;COUNT=5;BYSETPOS=1\nRDATE:20240110T090000Z\nEXDATE:20240102T090000Z,20240102T100000Z,20240110T090000Z")
+	set.RDate(time.Date(2024, 1, 11, 9, 0, 0, 0, Floating))
+	want := []string{
+		"error: BYSETPOS must be used with another BYxxx part (RFC 5545, section 3.3.10)",
+		"warning: RDATE Thu Jan 11 2024 09:00:00 Floating and DTSTART are not both floating times (RFC 5545, section 3.8.5.2)",
+		"warning: EXDATE Tue Jan 2 2024 10:00:00 UTC excludes no occurrence (RFC 5545, section 3.8.5.1)",
+	}
+	issues := set.Lint()
+	if len(issues) != len(want) {
+		t.Fatalf("get %v, want %v", issues, want)
+	}
+	for i, issue := range issues {
+		if issue.String() != want[i] {
+			t.Errorf("get %v, want %v", issue, want[i])
+		}
+	}
+}
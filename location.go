@@ -0,0 +1,176 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"errors"
+	"sort"
+	"time"
+)
+
+// LocationMode denotes how InLocation converts the times of a recurrence.
+type LocationMode int
+
+// Location modes
+const (
+	// KeepWallClock keeps the wall-clock times, 9am stays 9am in the new location.
+	KeepWallClock LocationMode = iota
+	// KeepInstant keeps the absolute instants, 9am in New York becomes 2pm in London.
+	KeepInstant
+)
+
+// ErrInstantNotPreserved is returned by InLocation when the rule cannot be rewritten
+// in the new location so that its occurrences keep their instants.
+var ErrInstantNotPreserved = errors.New("occurrences cannot keep their instants in the location")
+
+// InLocation returns a copy of the rule with DTSTART and UNTIL converted to loc.
+//
+// With KeepWallClock, the times keep their date and clock in loc.
+// With KeepInstant, the times keep their instant, and BYHOUR, BYMINUTE and BYSECOND
+// are shifted by the offset between the locations at DTSTART. The instants of
+// later occurrences are only preserved while that offset does not change, that is
+// as long as the daylight saving time of the locations agree. ErrInstantNotPreserved
+// is returned when the shifted times of day do not form BYxxx parts again, or move
+// some occurrences to another day while the rule selects days explicitly.
+//
+// Floating times have no instant, they keep their wall-clock time in both modes,
+// so that KeepInstant resolves floating times in loc while the others keep their instant.
+func (r *RRule) InLocation(loc *time.Location, mode LocationMode) (*RRule, error) {
+	option := r.OrigOptions
+	if mode == KeepInstant && !IsFloating(r.dtstart) {
+		var err error
+		if option, err = r.shiftedOptions(loc); err != nil {
+			return nil, err
+		}
+	} else {
+		option.Dtstart = wallClockIn(r.dtstart, loc)
+		option.Until = convertIn(option.Until, loc, mode)
+	}
+	return r.withOptions(option), nil
+}
+
+// InLocation returns a copy of the set with DTSTART, its RRULE, its RDATEs and EXDATEs
+// and its overrides converted to loc. See RRule.InLocation.
+func (set *Set) InLocation(loc *time.Location, mode LocationMode) (*Set, error) {
+	convert := func(t time.Time) time.Time { return convertIn(t, loc, mode) }
+	result := set.clone()
+	if set.rrule != nil {
+		r, err := set.rrule.InLocation(loc, mode)
+		if err != nil {
+			return nil, err
+		}
+		result.rrule = r
+	}
+	result.dtstart = convert(set.dtstart)
+	for i := range result.rdate {
+		result.rdate[i] = convert(result.rdate[i])
+	}
+	for i := range result.exdate {
+		result.exdate[i] = convert(result.exdate[i])
+	}
+	for i := range result.overrides {
+		o := &result.overrides[i]
+		o.RecurrenceID = convert(o.RecurrenceID)
+		if !o.Start.IsZero() {
+			o.Start = convert(o.Start)
+		}
+	}
+	return result, nil
+}
+
+// convertIn converts t to loc according to mode, floating times keep their wall-clock time.
+func convertIn(t time.Time, loc *time.Location, mode LocationMode) time.Time {
+	if mode == KeepInstant && !IsFloating(t) {
+		return t.In(loc)
+	}
+	return wallClockIn(t, loc)
+}
+
+// wallClockIn returns the time with the same date and clock as t in loc.
+// The zero time is kept.
+func wallClockIn(t time.Time, loc *time.Location) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	return time.Date(year, month, day, hour, minute, second, t.Nanosecond(), loc)
+}
+
+// shiftedOptions returns the options of the rule in loc, with DTSTART and UNTIL
+// keeping their instant, and BYHOUR, BYMINUTE and BYSECOND shifted by the offset
+// between the locations at DTSTART.
+func (r *RRule) shiftedOptions(loc *time.Location) (ROption, error) {
+	option := r.OrigOptions
+	dtstart := r.dtstart.In(loc)
+	option.Dtstart = dtstart
+	if !option.Until.IsZero() {
+		option.Until = convertIn(option.Until, loc, KeepInstant)
+	}
+	_, before := r.dtstart.Zone()
+	_, after := dtstart.Zone()
+	delta := after - before
+	if delta == 0 {
+		return option, nil
+	}
+
+	// The times of day the rule can produce, those of DTSTART for the fields it fixes.
+	values := func(explicit []int, fixed bool, value, n int) []int {
+		if len(explicit) != 0 {
+			return explicit
+		}
+		if fixed {
+			return []int{value}
+		}
+		return rang(0, n)
+	}
+	hours := values(option.Byhour, r.freq < HOURLY, r.dtstart.Hour(), 24)
+	minutes := values(option.Byminute, r.freq < MINUTELY, r.dtstart.Minute(), 60)
+	seconds := values(option.Bysecond, r.freq < SECONDLY, r.dtstart.Second(), 60)
+
+	shifted := map[int]bool{}
+	newHours, newMinutes, newSeconds := map[int]bool{}, map[int]bool{}, map[int]bool{}
+	carries := map[int]bool{}
+	for _, hour := range hours {
+		for _, minute := range minutes {
+			for _, second := range seconds {
+				carry, t := divmod(hour*3600+minute*60+second+delta, 86400)
+				carries[carry] = true
+				shifted[t] = true
+				newHours[t/3600], newMinutes[t/60%60], newSeconds[t%60] = true, true, true
+			}
+		}
+	}
+	// The shifted times of day must be all the combinations of the shifted parts.
+	if len(shifted) != len(newHours)*len(newMinutes)*len(newSeconds) {
+		return option, ErrInstantNotPreserved
+	}
+	// Occurrences moved to another day are only preserved if the days are not selected
+	// explicitly, and, for rules whose periods are days or longer or that select
+	// positions, if they all move.
+	days := len(option.Byweekday) != 0 || len(option.Bymonthday) != 0 || len(option.Byyearday) != 0 ||
+		len(option.Byweekno) != 0 || len(option.Byeaster) != 0 || len(option.Bymonth) != 0
+	if days && (len(carries) > 1 || !carries[0]) ||
+		(r.freq < DAILY || len(option.Bysetpos) != 0) && len(carries) > 1 {
+		return option, ErrInstantNotPreserved
+	}
+
+	keys := func(m map[int]bool) []int {
+		var result []int
+		for k := range m {
+			result = append(result, k)
+		}
+		sort.Ints(result)
+		return result
+	}
+	if len(option.Byhour) != 0 {
+		option.Byhour = keys(newHours)
+	}
+	if len(option.Byminute) != 0 {
+		option.Byminute = keys(newMinutes)
+	}
+	if len(option.Bysecond) != 0 {
+		option.Bysecond = keys(newSeconds)
+	}
+	return option, nil
+}
@@ -0,0 +1,92 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+// instantsEqual compares times regardless of their location.
+func instantsEqual(value, want []time.Time) bool {
+	if len(value) != len(want) {
+		return false
+	}
+	for i := range value {
+		if !value[i].Equal(want[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestInLocationWallClock(t *testing.T) {
+	newYork, _ := time.LoadLocation("America/New_York")
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	r, _ := NewRRule(ROption{Freq: WEEKLY, Byweekday: []Weekday{MO, WE}, Count: 4,
+		Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, newYork)})
+	set := Set{}
+	set.RRule(r)
+	set.RDate(time.Date(2024, 1, 6, 9, 0, 0, 0, newYork))
+	set.ExDate(time.Date(2024, 1, 3, 9, 0, 0, 0, newYork))
+
+	result, err := set.InLocation(tokyo, KeepWallClock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Time{
+		time.Date(2024, 1, 1, 9, 0, 0, 0, tokyo),
+		time.Date(2024, 1, 6, 9, 0, 0, 0, tokyo),
+		time.Date(2024, 1, 8, 9, 0, 0, 0, tokyo),
+		time.Date(2024, 1, 10, 9, 0, 0, 0, tokyo),
+	}
+	if value := result.All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+	if value := result.All()[0].Location(); value != tokyo {
+		t.Errorf("get %v, want %v", value, tokyo)
+	}
+	if value := set.GetRRule().GetDTStart().Location(); value != newYork {
+		t.Errorf("the original set was modified: %v", value)
+	}
+}
+
+func TestInLocationInstant(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	r, _ := NewRRule(ROption{Freq: DAILY, Byhour: []int{10, 22}, Count: 6,
+		Dtstart: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)})
+	set := Set{}
+	set.RRule(r)
+	set.Override(Override{RecurrenceID: time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), Start: time.Date(2024, 1, 2, 11, 0, 0, 0, time.UTC)})
+
+	result, err := set.InLocation(tokyo, KeepInstant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value := result.GetRRule().OrigOptions.Byhour; len(value) != 2 || value[0] != 7 || value[1] != 19 {
+		t.Errorf("get BYHOUR %v, want 7 and 19", value)
+	}
+	if value, want := result.All(), set.All(); !instantsEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+	if value := result.All()[0].Location(); value != tokyo {
+		t.Errorf("get %v, want %v", value, tokyo)
+	}
+}
+
+func TestInLocationInstantNotPreserved(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	r, _ := NewRRule(ROption{Freq: WEEKLY, Byweekday: []Weekday{MO}, Dtstart: time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC)})
+	if _, err := r.InLocation(tokyo, KeepInstant); err != ErrInstantNotPreserved {
+		t.Errorf("get %v, want ErrInstantNotPreserved", err)
+	}
+	// Without explicit days, the weekday follows DTSTART.
+	r, _ = NewRRule(ROption{Freq: WEEKLY, Count: 3, Dtstart: time.Date(2024, 1, 1, 22, 0, 0, 0, time.UTC)})
+	result, err := r.InLocation(tokyo, KeepInstant)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, want := result.All(), r.All(); !instantsEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
@@ -0,0 +1,89 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"container/heap"
+	"time"
+)
+
+type genItem struct {
+	dt     time.Time
+	gen    Next
+	source Source
+	id     int
+	index  int
+}
+
+// genItemSlice is a min-heap of iterators ordered by their next time.
+type genItemSlice []genItem
+
+func (s genItemSlice) Len() int      { return len(s) }
+func (s genItemSlice) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s genItemSlice) Less(i, j int) bool {
+	return s[i].dt.Before(s[j].dt) || s[i].dt.Equal(s[j].dt) && s[i].source < s[j].source
+}
+
+func (s *genItemSlice) Push(x interface{}) { *s = append(*s, x.(genItem)) }
+
+func (s *genItemSlice) Pop() interface{} {
+	old := *s
+	item := old[len(old)-1]
+	*s = old[:len(old)-1]
+	return item
+}
+
+// merger merges ascending iterators into a single ascending stream,
+// keeping them in a heap so that each step costs O(log k) for k iterators.
+// Equal times of different iterators are ordered by source.
+type merger struct {
+	items genItemSlice
+	live  []bool
+}
+
+// add adds an iterator to the merge. Iterators are identified by the order they are added in.
+func (m *merger) add(next Next, source Source) {
+	dt, ok := next()
+	m.live = append(m.live, ok)
+	if ok {
+		heap.Push(&m.items, genItem{dt: dt, gen: next, source: source, id: len(m.live) - 1})
+	}
+}
+
+// empty reports whether all the iterators are exhausted.
+func (m *merger) empty() bool {
+	return len(m.items) == 0
+}
+
+// alive reports whether the iterator id is not exhausted.
+func (m *merger) alive(id int) bool {
+	return m.live[id]
+}
+
+// peek returns the next time of the merge, which must not be empty.
+func (m *merger) peek() time.Time {
+	return m.items[0].dt
+}
+
+// pop returns the next time of the merge, which must not be empty, with the iterator
+// it comes from and its index in that iterator, and advances that iterator.
+func (m *merger) pop() genItem {
+	item := m.items[0]
+	head := &m.items[0]
+	head.index++
+	var ok bool
+	if head.dt, ok = head.gen(); ok {
+		heap.Fix(&m.items, 0)
+	} else {
+		m.live[head.id] = false
+		heap.Pop(&m.items)
+	}
+	return item
+}
+
+// skipBefore discards the times of the merge before t.
+func (m *merger) skipBefore(t time.Time) {
+	for !m.empty() && m.peek().Before(t) {
+		m.pop()
+	}
+}
@@ -0,0 +1,64 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"time"
+)
+
+// Source denotes where an occurrence of a Set comes from.
+type Source int
+
+// Sources of occurrences
+const (
+	// SourceRule is an occurrence generated by an RRULE.
+	SourceRule Source = iota
+	// SourceRDate is an occurrence added with an RDATE.
+	SourceRDate
+)
+
+func (s Source) String() string {
+	switch s {
+	case SourceRule:
+		return "RRULE"
+	case SourceRDate:
+		return "RDATE"
+	}
+	return ""
+}
+
+// Occurrence is an occurrence of a Set annotated with its origin.
+type Occurrence struct {
+	// Time is the effective time of the occurrence.
+	Time time.Time
+	// RecurrenceID is the original time of the occurrence, Time unless it is overridden.
+	RecurrenceID time.Time
+	// Source tells whether the occurrence comes from a rule or an RDATE.
+	// An occurrence that is both comes from the rule.
+	Source Source
+	// Rule is the index of the rule that generated the occurrence, -1 for RDATEs.
+	Rule int
+	// Index is the zero-based position of the occurrence in its rule, see RRule.Nth.
+	// It is -1 for RDATEs.
+	Index int
+	// Override is the override applied to the occurrence, if any.
+	Override *Override
+}
+
+// AnnotatedIterator returns an iterator over the occurrences of the rrule.Set,
+// like Iterator, that reports where each occurrence comes from.
+func (set *Set) AnnotatedIterator() func() (Occurrence, bool) {
+	next, _ := set.occurrences(context.Background())
+	return next
+}
+
+// annotate returns the occurrence of the set whose original time is t, before overrides are applied.
+func (set *Set) annotate(t time.Time) Occurrence {
+	if set.rrule != nil {
+		if i, ok := set.rrule.IndexOf(t); ok {
+			return Occurrence{Time: t, RecurrenceID: t, Source: SourceRule, Index: i}
+		}
+	}
+	return Occurrence{Time: t, RecurrenceID: t, Source: SourceRDate, Rule: -1, Index: -1}
+}
@@ -0,0 +1,48 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAnnotatedIterator(t *testing.T) {
+	set := overrideSet()
+	set.RDate(time.Date(1997, 9, 3, 12, 0, 0, 0, time.UTC))
+	set.RDate(time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC))
+	set.ExDate(time.Date(1997, 9, 5, 9, 0, 0, 0, time.UTC))
+	set.Override(Override{RecurrenceID: time.Date(1997, 9, 6, 9, 0, 0, 0, time.UTC), Start: time.Date(1997, 9, 2, 15, 0, 0, 0, time.UTC)})
+
+	want := []Occurrence{
+		{Time: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC), Source: SourceRule, Index: 0},
+		{Time: time.Date(1997, 9, 2, 15, 0, 0, 0, time.UTC), RecurrenceID: time.Date(1997, 9, 6, 9, 0, 0, 0, time.UTC), Source: SourceRule, Index: 4},
+		{Time: time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC), Source: SourceRule, Index: 1},
+		{Time: time.Date(1997, 9, 3, 12, 0, 0, 0, time.UTC), Source: SourceRDate, Rule: -1, Index: -1},
+		// An RDATE that is also generated by the rule comes from the rule.
+		{Time: time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC), Source: SourceRule, Index: 2},
+	}
+	next := set.AnnotatedIterator()
+	for _, w := range want {
+		if w.RecurrenceID.IsZero() {
+			w.RecurrenceID = w.Time
+		}
+		o, ok := next()
+		if !ok || !o.Time.Equal(w.Time) || !o.RecurrenceID.Equal(w.RecurrenceID) ||
+			o.Source != w.Source || o.Rule != w.Rule || o.Index != w.Index {
+			t.Errorf("get %+v, want %+v", o, w)
+		}
+		if (o.Override != nil) != !o.Time.Equal(o.RecurrenceID) {
+			t.Errorf("get override %v for %v", o.Override, o.Time)
+		}
+	}
+	if o, ok := next(); ok {
+		t.Errorf("get %+v, want no more occurrence", o)
+	}
+}
+
+func TestSourceString(t *testing.T) {
+	if SourceRule.String() != "RRULE" || SourceRDate.String() != "RDATE" {
+		t.Errorf("get %v and %v", SourceRule, SourceRDate)
+	}
+}
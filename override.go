@@ -0,0 +1,136 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Override modifies a single occurrence of a Set, like a VEVENT with a RECURRENCE-ID.
+// It only applies if its RecurrenceID is an occurrence of the set.
+type Override struct {
+	// RecurrenceID is the original time of the occurrence.
+	RecurrenceID time.Time
+	// Start is the effective time of the occurrence, the zero value keeps RecurrenceID.
+	Start time.Time
+	// Duration is the effective duration of the occurrence, zero keeps the duration of the set.
+	Duration time.Duration
+	// Payload is left untouched for the caller, e.g. the modified event.
+	Payload interface{}
+}
+
+// effectiveStart returns the time at which the overridden occurrence takes place.
+func (o *Override) effectiveStart() time.Time {
+	if o.Start.IsZero() {
+		return o.RecurrenceID
+	}
+	return o.Start
+}
+
+// Override adds an override to the set, replacing any override with the same RecurrenceID.
+// Times will be truncated to second precision.
+func (set *Set) Override(o Override) {
+	o.RecurrenceID = o.RecurrenceID.Truncate(time.Second)
+	o.Start = o.Start.Truncate(time.Second)
+	for i := range set.overrides {
+		if set.overrides[i].RecurrenceID.Equal(o.RecurrenceID) {
+			set.overrides[i] = o
+			return
+		}
+	}
+	set.overrides = append(set.overrides, o)
+}
+
+// SetOverrides sets the overrides of the set.
+// Times will be truncated to second precision.
+func (set *Set) SetOverrides(overrides []Override) {
+	set.overrides = nil
+	for _, o := range overrides {
+		set.Override(o)
+	}
+}
+
+// GetOverrides returns the overrides of the set
+func (set *Set) GetOverrides() []Override {
+	return set.overrides
+}
+
+// appliedOverrides returns the overrides whose RecurrenceID is an occurrence of the set,
+// sorted by effective start.
+func (set *Set) appliedOverrides() []*Override {
+	var applied []*Override
+	for i := range set.overrides {
+		if set.baseContains(set.overrides[i].RecurrenceID) {
+			applied = append(applied, &set.overrides[i])
+		}
+	}
+	sort.SliceStable(applied, func(i, j int) bool {
+		return applied[i].effectiveStart().Before(applied[j].effectiveStart())
+	})
+	return applied
+}
+
+// overrideOf returns the applied override whose RecurrenceID is t, or nil.
+func overrideOf(applied []*Override, t time.Time) *Override {
+	for _, o := range applied {
+		if o.RecurrenceID.Equal(t) {
+			return o
+		}
+	}
+	return nil
+}
+
+// occurrences merges the occurrences of the set that are not overridden with the
+// effective starts of the applied overrides.
+func (set *Set) occurrences(ctx context.Context) (func() (Occurrence, bool), func() error) {
+	base, err := set.baseIterator(ctx)
+	applied := set.appliedOverrides()
+	return set.withOverrides(base, applied, applied), err
+}
+
+// withOverrides merges the occurrences of base that are not overridden by the applied
+// overrides with the effective starts of moved, the applied overrides that base has not reached yet.
+func (set *Set) withOverrides(base func() (Occurrence, bool), applied, moved []*Override) func() (Occurrence, bool) {
+	var head Occurrence
+	hasHead := false
+	lastdt := time.Time{}
+	return func() (Occurrence, bool) {
+		for {
+			for !hasHead {
+				v, ok := base()
+				if !ok {
+					break
+				}
+				if overrideOf(applied, v.Time) == nil {
+					head, hasHead = v, true
+				}
+			}
+
+			var o Occurrence
+			if len(moved) != 0 && (!hasHead || !head.Time.Before(moved[0].effectiveStart())) {
+				o = set.annotate(moved[0].RecurrenceID)
+				o.Time, o.Override = moved[0].effectiveStart(), moved[0]
+				moved = moved[1:]
+			} else if hasHead {
+				o = head
+				hasHead = false
+			} else {
+				return Occurrence{}, false
+			}
+			if lastdt.IsZero() || !lastdt.Equal(o.Time) {
+				lastdt = o.Time
+				return o, true
+			}
+		}
+	}
+}
+
+// baseContains reports whether t is an occurrence of the set before overrides are applied.
+func (set *Set) baseContains(t time.Time) bool {
+	if timeContains(set.exdate, t) {
+		return false
+	}
+	return timeContains(set.rdate, t) || set.rrule != nil && set.rrule.Contains(t)
+}
@@ -0,0 +1,124 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"testing"
+	"time"
+)
+
+func overrideSet() *Set {
+	r, _ := NewRRule(ROption{Freq: DAILY, Count: 5,
+		Dtstart: time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC)})
+	set := &Set{}
+	set.RRule(r)
+	set.Duration(time.Hour)
+	return set
+}
+
+func TestOverrideMoves(t *testing.T) {
+	set := overrideSet()
+	set.Override(Override{
+		RecurrenceID: time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC),
+		Start:        time.Date(1997, 9, 5, 15, 0, 0, 0, time.UTC),
+		Payload:      "moved",
+	})
+	want := []time.Time{
+		time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 5, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 5, 15, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 6, 9, 0, 0, 0, time.UTC),
+	}
+	if value := set.All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+
+	if set.Contains(time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC)) {
+		t.Error("the original time of a moved occurrence should not be contained")
+	}
+	if !set.Contains(time.Date(1997, 9, 5, 15, 0, 0, 0, time.UTC)) {
+		t.Error("the effective time of a moved occurrence should be contained")
+	}
+	if n, _ := set.Count(); n != 5 {
+		t.Errorf("get %v, want 5", n)
+	}
+	if n := set.CountBetween(time.Date(1997, 9, 3, 0, 0, 0, 0, time.UTC), time.Date(1997, 9, 5, 12, 0, 0, 0, time.UTC), true); n != 2 {
+		t.Errorf("get %v, want 2", n)
+	}
+	if i, ok := set.IndexOf(time.Date(1997, 9, 5, 15, 0, 0, 0, time.UTC)); !ok || i != 3 {
+		t.Errorf("get %v %v, want 3", i, ok)
+	}
+}
+
+func TestOverrideInstances(t *testing.T) {
+	set := overrideSet()
+	o := Override{
+		RecurrenceID: time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC),
+		Start:        time.Date(1997, 9, 3, 15, 0, 0, 0, time.UTC),
+		Duration:     2 * time.Hour,
+	}
+	set.Override(o)
+	value := set.Instances(time.Date(1997, 9, 3, 0, 0, 0, 0, time.UTC), time.Date(1997, 9, 4, 0, 0, 0, 0, time.UTC))
+	if len(value) != 1 {
+		t.Fatalf("get %v, want one instance", value)
+	}
+	i := value[0]
+	if !i.Start.Equal(o.Start) || !i.End.Equal(o.Start.Add(2*time.Hour)) ||
+		!i.RecurrenceID.Equal(o.RecurrenceID) || i.Override == nil || *i.Override != o {
+		t.Errorf("get %+v, want the override", i)
+	}
+}
+
+func TestOverrideOnlyDuration(t *testing.T) {
+	set := overrideSet()
+	set.Override(Override{RecurrenceID: time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC), Duration: 3 * time.Hour})
+	if value, want := set.All(), overrideSet().All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+	value := set.Instances(time.Date(1997, 9, 4, 11, 0, 0, 0, time.UTC), time.Date(1997, 9, 5, 0, 0, 0, 0, time.UTC))
+	if len(value) != 1 || !value[0].End.Equal(time.Date(1997, 9, 4, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("get %v, want the instance with the duration of the override", value)
+	}
+}
+
+func TestOverrideReplaced(t *testing.T) {
+	set := overrideSet()
+	id := time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC)
+	set.Override(Override{RecurrenceID: id, Start: id.Add(time.Hour)})
+	set.Override(Override{RecurrenceID: id, Start: id.Add(2 * time.Hour)})
+	if len(set.GetOverrides()) != 1 || !set.GetOverrides()[0].Start.Equal(id.Add(2*time.Hour)) {
+		t.Errorf("get %v, want the last override", set.GetOverrides())
+	}
+}
+
+func TestOverrideNotApplied(t *testing.T) {
+	set := overrideSet()
+	set.ExDate(time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC))
+	// Neither an excluded occurrence nor a time that is not an occurrence can be overridden.
+	set.SetOverrides([]Override{
+		{RecurrenceID: time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC), Start: time.Date(1997, 9, 3, 15, 0, 0, 0, time.UTC)},
+		{RecurrenceID: time.Date(1997, 9, 4, 10, 0, 0, 0, time.UTC), Start: time.Date(1997, 9, 4, 15, 0, 0, 0, time.UTC)},
+	})
+	want := []time.Time{
+		time.Date(1997, 9, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 5, 9, 0, 0, 0, time.UTC),
+		time.Date(1997, 9, 6, 9, 0, 0, 0, time.UTC),
+	}
+	if value := set.All(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+	if n, _ := set.Count(); n != 4 {
+		t.Errorf("get %v, want 4", n)
+	}
+}
+
+func TestOverrideOntoOccurrence(t *testing.T) {
+	set := overrideSet()
+	// Moving an occurrence onto another one merges them.
+	set.Override(Override{RecurrenceID: time.Date(1997, 9, 3, 9, 0, 0, 0, time.UTC), Start: time.Date(1997, 9, 4, 9, 0, 0, 0, time.UTC)})
+	if n, _ := set.Count(); n != len(set.All()) || n != 4 {
+		t.Errorf("get %v, want %v", n, len(set.All()))
+	}
+}
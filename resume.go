@@ -0,0 +1,143 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"time"
+)
+
+// ErrInvalidToken is returned by Resume when the token is malformed.
+var ErrInvalidToken = errors.New("invalid iterator token")
+
+// ErrRuleChanged is returned by Resume when the token was issued
+// for another rule, or for the rule before it changed.
+var ErrRuleChanged = errors.New("rule changed since the token was issued")
+
+// ResumableIterator is an iterator over the occurrences of an RRule whose state can be
+// saved as a token at any time, and restored later, possibly in another process,
+// with RRule.Resume. The token holds the current period, the number of occurrences
+// generated so far, the remaining COUNT and the occurrences generated but not yet returned,
+// along with a fingerprint of the rule.
+type ResumableIterator struct {
+	iterator *rIterator
+}
+
+// ResumableIterator returns a ResumableIterator starting at the first occurrence of the RRule.
+func (r *RRule) ResumableIterator() *ResumableIterator {
+	return &ResumableIterator{r.iterator(context.Background())}
+}
+
+// Next returns the next occurrence and true if it exists, else zero value and false.
+func (it *ResumableIterator) Next() (time.Time, bool) {
+	return it.iterator.next()
+}
+
+// Err returns the *ExpansionError that stopped the iterator if it ran out
+// of the period budget of the rule, or nil. A token taken after such an error
+// resumes the expansion where it stopped, with a new budget.
+func (it *ResumableIterator) Err() error {
+	return it.iterator.err
+}
+
+const tokenVersion = 1
+
+// Token returns the state of the iterator as a compact URL-safe string.
+func (it *ResumableIterator) Token() string {
+	iterator := it.iterator
+	r := iterator.ii.rrule
+	buf := make([]byte, 9, 9+(10+iterator.remain.Len())*binary.MaxVarintLen64)
+	buf[0] = tokenVersion
+	binary.BigEndian.PutUint64(buf[1:], r.fingerprint())
+	tmp := make([]byte, binary.MaxVarintLen64)
+	put := func(v int64) {
+		buf = append(buf, tmp[:binary.PutVarint(tmp, v)]...)
+	}
+	finished := 0
+	if iterator.finished && iterator.err == nil {
+		finished = 1
+	}
+	for _, v := range []int{iterator.year, int(iterator.month), iterator.day, iterator.hour, iterator.minute,
+		iterator.second, iterator.weekday, iterator.total, iterator.count, finished, iterator.remain.Len()} {
+		put(int64(v))
+	}
+	// Pending occurrences are ascending, they are stored as differences.
+	last := int64(0)
+	for _, v := range iterator.remain.storage {
+		put(v.Unix() - last)
+		last = v.Unix()
+	}
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// Resume restores a ResumableIterator from a token returned by Token for the same rule.
+// It returns ErrRuleChanged if the rule is not the one the token was issued for,
+// comparing the fingerprints of their DTSTART, location and RRULE parts.
+func (r *RRule) Resume(token string) (*ResumableIterator, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(buf) < 9 || buf[0] != tokenVersion {
+		return nil, ErrInvalidToken
+	}
+	if binary.BigEndian.Uint64(buf[1:]) != r.fingerprint() {
+		return nil, ErrRuleChanged
+	}
+	buf = buf[9:]
+	get := func() int {
+		v, n := binary.Varint(buf)
+		if n <= 0 {
+			err = ErrInvalidToken
+			return 0
+		}
+		buf = buf[n:]
+		return int(v)
+	}
+
+	iterator := &rIterator{ctx: context.Background()}
+	iterator.year, iterator.month, iterator.day = get(), time.Month(get()), get()
+	iterator.hour, iterator.minute, iterator.second = get(), get(), get()
+	iterator.weekday, iterator.total, iterator.count = get(), get(), get()
+	iterator.finished = get() == 1
+	n := get()
+	if err != nil || n < 0 || n > len(buf) || iterator.month < 1 || iterator.month > 12 || iterator.day < 1 ||
+		iterator.year < 1 || iterator.year > MAXYEAR {
+		return nil, ErrInvalidToken
+	}
+	last := int64(0)
+	for i := 0; i < n; i++ {
+		last += int64(get())
+		iterator.remain.Append(time.Unix(last, 0).In(r.dtstart.Location()))
+	}
+	if err != nil || len(buf) != 0 {
+		return nil, ErrInvalidToken
+	}
+
+	// An iterator stopped while advancing may hold a day past the end of its month.
+	year, month, day := time.Date(iterator.year, iterator.month, iterator.day, 0, 0, 0, 0, time.UTC).Date()
+	iterator.year, iterator.month, iterator.day = year, month, day
+	iterator.ii = iterInfo{rrule: r}
+	iterator.ii.rebuild(iterator.year, iterator.month)
+	// The timeset is derived from the current period, like in Iterator.
+	if r.freq < HOURLY {
+		iterator.timeset = r.timeset
+	} else if !(len(r.byhour) != 0 && !contains(r.byhour, iterator.hour) ||
+		r.freq >= MINUTELY && len(r.byminute) != 0 && !contains(r.byminute, iterator.minute) ||
+		r.freq >= SECONDLY && len(r.bysecond) != 0 && !contains(r.bysecond, iterator.second)) {
+		iterator.ii.fillTimeSet(&iterator.timeset, r.freq, iterator.hour, iterator.minute, iterator.second)
+	}
+	return &ResumableIterator{iterator}, nil
+}
+
+// fingerprint returns a hash of the rule, its effective DTSTART and its location included.
+func (r *RRule) fingerprint() uint64 {
+	option := r.OrigOptions
+	option.Dtstart = r.dtstart
+	h := fnv.New64a()
+	h.Write([]byte(option.String()))
+	h.Write([]byte{0})
+	h.Write([]byte(r.dtstart.Location().String()))
+	return h.Sum64()
+}
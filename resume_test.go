@@ -0,0 +1,94 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package rrule
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestResume(t *testing.T) {
+	for _, s := range []string{
+		"DTSTART:19970902T090000Z\nRRULE:FREQ=DAILY;COUNT=10",
+		"DTSTART:19970902T090000Z\nRRULE:FREQ=WEEKLY;COUNT=13;BYDAY=TU,TH;BYHOUR=9,17",
+		"DTSTART:19970902T090000Z\nRRULE:FREQ=MONTHLY;COUNT=7;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=1,-1",
+		"DTSTART:19970902T090000Z\nRRULE:FREQ=YEARLY;UNTIL=20010101T000000Z;BYMONTH=1,3;BYMONTHDAY=1,-1",
+		"DTSTART:19970902T000000Z\nRRULE:FREQ=HOURLY;INTERVAL=5;COUNT=9;BYHOUR=0,5,10",
+		"DTSTART;TZID=America/New_York:20241026T013000\nRRULE:FREQ=WEEKLY;COUNT=12;BYDAY=SA,SU",
+	} {
+		r, err := StrToRRule(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := r.All()
+		for k := 0; k <= len(want); k++ {
+			it := r.ResumableIterator()
+			for i := 0; i < k; i++ {
+				it.Next()
+			}
+			token := it.Token()
+			// The token is restored against a new copy of the rule.
+			same, _ := StrToRRule(s)
+			resumed, err := same.Resume(token)
+			if err != nil {
+				t.Fatalf("%s after %d: %v", s, k, err)
+			}
+			if value := all(resumed.Next); !instantsEqual(value, want[k:]) {
+				t.Errorf("%s after %d: get %v, want %v", s, k, value, want[k:])
+			}
+		}
+	}
+}
+
+func TestResumeInChunks(t *testing.T) {
+	s := "DTSTART:19970902T090000Z\nRRULE:FREQ=HOURLY;BYHOUR=9;BYMINUTE=0,30;COUNT=20"
+	r, _ := StrToRRule(s)
+	want := r.All()
+
+	// Each chunk examines at most 30 periods, and the next one resumes from its token.
+	var value []time.Time
+	token := r.ResumableIterator().Token()
+	for chunks := 0; ; chunks++ {
+		if chunks > 100 {
+			t.Fatal("too many chunks")
+		}
+		chunk, _ := StrToRRule(s)
+		chunk.SetMaxPeriods(30)
+		it, err := chunk.Resume(token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value = append(value, all(it.Next)...)
+		if !errors.Is(it.Err(), ErrMaxPeriods) {
+			break
+		}
+		token = it.Token()
+	}
+	if !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
+
+func TestResumeErrors(t *testing.T) {
+	r, _ := StrToRRule("DTSTART:19970902T090000Z\nRRULE:FREQ=DAILY;COUNT=10")
+	it := r.ResumableIterator()
+	it.Next()
+	token := it.Token()
+	if _, err := r.Resume("garbage"); err != ErrInvalidToken {
+		t.Errorf("get %v, want ErrInvalidToken", err)
+	}
+	if _, err := r.Resume(token[:len(token)-2]); err != ErrInvalidToken {
+		t.Errorf("get %v, want ErrInvalidToken", err)
+	}
+	for _, s := range []string{
+		"DTSTART:19970902T090000Z\nRRULE:FREQ=DAILY;COUNT=11",
+		"DTSTART:19970902T100000Z\nRRULE:FREQ=DAILY;COUNT=10",
+		"DTSTART;TZID=America/New_York:19970902T090000\nRRULE:FREQ=DAILY;COUNT=10",
+	} {
+		other, _ := StrToRRule(s)
+		if _, err := other.Resume(token); err != ErrRuleChanged {
+			t.Errorf("%s: get %v, want ErrRuleChanged", s, err)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package rrule
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"sort"
@@ -90,7 +91,8 @@ var (
 )
 
 // ROption offers options to construct a RRule instance.
-// For performance, it is strongly recommended providing explicit ROption.Dtstart, which defaults to `time.Now().UTC().Truncate(time.Second)`.
+// For performance, it is strongly recommended providing explicit ROption.Dtstart, which defaults to `time.Now().UTC().Truncate(time.Second)`,
+// or to the time of the clock given with WithClock.
 type ROption struct {
 	Freq       Frequency
 	Dtstart    time.Time
@@ -134,20 +136,36 @@ type RRule struct {
 	byeaster                []int
 	timeset                 []time.Time
 	len                     int
+	maxPeriods              int
+	duration                time.Duration
+	clock                   Clock
 }
 
 // NewRRule construct a new RRule instance
-func NewRRule(arg ROption) (*RRule, error) {
+func NewRRule(arg ROption, opts ...Option) (*RRule, error) {
 	if err := validateBounds(arg); err != nil {
 		return nil, err
 	}
-	r := buildRRule(arg)
+	o := newOptions(opts)
+	if o.strict {
+		for _, issue := range Lint(arg) {
+			if issue.Severity == Error {
+				return nil, errors.New(issue.String())
+			}
+		}
+	}
+	r := buildRRule(arg, o.clock)
 	return &r, nil
 }
 
-func buildRRule(arg ROption) RRule {
+func buildRRule(arg ROption, clock Clock) RRule {
+	if clock == nil {
+		// Rules built without NewRRule, such as RRule{}, have no clock.
+		clock = systemClock{}
+	}
 	r := RRule{}
 	r.OrigOptions = arg
+	r.clock = clock
 	// FREQ default to YEARLY
 	r.freq = arg.Freq
 
@@ -164,7 +182,7 @@ func buildRRule(arg ROption) RRule {
 
 	// DTSTART default to now
 	if arg.Dtstart.IsZero() {
-		arg.Dtstart = time.Now().UTC()
+		arg.Dtstart = clock.Now().UTC()
 	}
 	arg.Dtstart = arg.Dtstart.Truncate(time.Second)
 	r.dtstart = arg.Dtstart
@@ -572,189 +590,270 @@ type rIterator struct {
 	remain   reusingRemainSlice
 	finished bool
 	dayset   []optInt
+	done     <-chan struct{}
+	ctx      context.Context
+	periods  int
+	err      error
+}
+
+// enterPeriod accounts for a new candidate period and reports whether
+// the expansion may go on, stopping it once the context is done or the
+// rule's period budget is exhausted.
+func (iterator *rIterator) enterPeriod() bool {
+	if iterator.done != nil {
+		select {
+		case <-iterator.done:
+			iterator.stop(iterator.ctx.Err())
+			return false
+		default:
+		}
+	}
+	if limit := iterator.ii.rrule.maxPeriods; limit > 0 && iterator.periods >= limit {
+		iterator.stop(ErrMaxPeriods)
+		return false
+	}
+	iterator.periods++
+	return true
+}
+
+func (iterator *rIterator) stop(err error) {
+	iterator.finished = true
+	iterator.err = &ExpansionError{Periods: iterator.periods, Err: err}
 }
 
 func (iterator *rIterator) generate() {
-	if iterator.finished {
-		return
+	for iterator.remain.Len() == 0 && !iterator.finished {
+		if !iterator.enterPeriod() {
+			return
+		}
+		filtered := iterator.filterDaySet()
+		iterator.emitPeriod()
+		if !iterator.finished {
+			iterator.advance(filtered)
+		}
 	}
+}
 
+// filterDaySet fills the dayset of the current period, leaving defined
+// only the days that pass the BYxxx filters, and reports whether any day was filtered out.
+func (iterator *rIterator) filterDaySet() (filtered bool) {
 	r := iterator.ii.rrule
-	for iterator.remain.Len() == 0 {
-		// Get dayset with the right frequency
-		setStart, setEnd := iterator.ii.calcDaySet(r.freq, iterator.year, iterator.month, iterator.day)
-		iterator.fillDaySetMonotonic(setStart, setEnd)
-
-		dayset := iterator.dayset
-		filtered := false
-
-		// Do the "hard" work ;-)
-		for dayIndex, day := range dayset {
-			i := day.Int
-			if len(r.bymonth) != 0 && !contains(r.bymonth, iterator.ii.mmask[i]) ||
-				len(r.byweekno) != 0 && iterator.ii.wnomask[i] == 0 ||
-				len(r.byweekday) != 0 && !contains(r.byweekday, iterator.ii.wdaymask[i]) ||
-				len(iterator.ii.nwdaymask) != 0 && iterator.ii.nwdaymask[i] == 0 ||
-				len(r.byeaster) != 0 && iterator.ii.eastermask[i] == 0 ||
-				(len(r.bymonthday) != 0 || len(r.bynmonthday) != 0) &&
-					!contains(r.bymonthday, iterator.ii.mdaymask[i]) &&
-					!contains(r.bynmonthday, iterator.ii.nmdaymask[i]) ||
-				len(r.byyearday) != 0 &&
-					(i < iterator.ii.yearlen &&
-						!contains(r.byyearday, i+1) &&
-						!contains(r.byyearday, -iterator.ii.yearlen+i) ||
-						i >= iterator.ii.yearlen &&
-							!contains(r.byyearday, i+1-iterator.ii.yearlen) &&
-							!contains(r.byyearday, -iterator.ii.nextyearlen+i-iterator.ii.yearlen)) {
-				dayset[dayIndex].Defined = false
-				filtered = true
-			}
+
+	// Get dayset with the right frequency
+	setStart, setEnd := iterator.ii.calcDaySet(r.freq, iterator.year, iterator.month, iterator.day)
+	iterator.fillDaySetMonotonic(setStart, setEnd)
+
+	dayset := iterator.dayset
+
+	// Do the "hard" work ;-)
+	for dayIndex, day := range dayset {
+		i := day.Int
+		if len(r.bymonth) != 0 && !contains(r.bymonth, iterator.ii.mmask[i]) ||
+			len(r.byweekno) != 0 && iterator.ii.wnomask[i] == 0 ||
+			len(r.byweekday) != 0 && !contains(r.byweekday, iterator.ii.wdaymask[i]) ||
+			len(iterator.ii.nwdaymask) != 0 && iterator.ii.nwdaymask[i] == 0 ||
+			len(r.byeaster) != 0 && iterator.ii.eastermask[i] == 0 ||
+			(len(r.bymonthday) != 0 || len(r.bynmonthday) != 0) &&
+				!contains(r.bymonthday, iterator.ii.mdaymask[i]) &&
+				!contains(r.bynmonthday, iterator.ii.nmdaymask[i]) ||
+			len(r.byyearday) != 0 &&
+				(i < iterator.ii.yearlen &&
+					!contains(r.byyearday, i+1) &&
+					!contains(r.byyearday, -iterator.ii.yearlen+i) ||
+					i >= iterator.ii.yearlen &&
+						!contains(r.byyearday, i+1-iterator.ii.yearlen) &&
+						!contains(r.byyearday, -iterator.ii.nextyearlen+i-iterator.ii.yearlen)) {
+			dayset[dayIndex].Defined = false
+			filtered = true
 		}
+	}
+	return filtered
+}
 
-		// Output results
-		if len(r.bysetpos) != 0 && len(iterator.timeset) != 0 {
-			var poslist []time.Time
-			for _, pos := range r.bysetpos {
-				var daypos, timepos int
-				if pos < 0 {
-					daypos, timepos = divmod(pos, len(iterator.timeset))
-				} else {
-					daypos, timepos = divmod(pos-1, len(iterator.timeset))
-				}
-				var temp []int
-				for _, day := range dayset {
-					if day.Defined {
-						temp = append(temp, day.Int)
-					}
-				}
-				i, err := pySubscript(temp, daypos)
-				if err != nil {
-					continue
-				}
-				timeTemp := iterator.timeset[timepos]
-				dateYear, dateMonth, dateDay := iterator.ii.firstyday.AddDate(0, 0, i).Date()
-				tempHour, tempMinute, tempSecond := timeTemp.Clock()
-				res := time.Date(dateYear, dateMonth, dateDay,
-					tempHour, tempMinute, tempSecond,
-					timeTemp.Nanosecond(), timeTemp.Location())
-				if !timeContains(poslist, res) {
-					poslist = append(poslist, res)
-				}
+// candidate returns the time of the given day of the year combined with a time of the timeset.
+func (iterator *rIterator) candidate(yday int, timeTemp time.Time) time.Time {
+	dateYear, dateMonth, dateDay := iterator.ii.firstyday.AddDate(0, 0, yday).Date()
+	tempHour, tempMinute, tempSecond := timeTemp.Clock()
+	return time.Date(dateYear, dateMonth, dateDay,
+		tempHour, tempMinute, tempSecond,
+		timeTemp.Nanosecond(), timeTemp.Location())
+}
+
+// emitPeriod outputs the occurrences of the current period.
+func (iterator *rIterator) emitPeriod() {
+	r := iterator.ii.rrule
+	if len(r.bysetpos) != 0 && len(iterator.timeset) != 0 {
+		for _, res := range iterator.setPositions() {
+			if !iterator.emit(res) {
+				return
 			}
-			sort.Sort(timeSlice(poslist))
-			for _, res := range poslist {
-				if !r.until.IsZero() && res.After(r.until) {
-					r.len = iterator.total
-					iterator.finished = true
+		}
+	} else {
+		for _, day := range iterator.dayset {
+			if !day.Defined {
+				continue
+			}
+			for _, timeTemp := range iterator.timeset {
+				if !iterator.emit(iterator.candidate(day.Int, timeTemp)) {
 					return
-				} else if !res.Before(r.dtstart) {
-					iterator.total++
-					iterator.remain.Append(res)
-					if iterator.count != 0 {
-						iterator.count--
-						if iterator.count == 0 {
-							r.len = iterator.total
-							iterator.finished = true
-							return
-						}
-					}
 				}
 			}
+		}
+	}
+}
+
+// setPositions returns the sorted times of the current period selected by BYSETPOS.
+func (iterator *rIterator) setPositions() []time.Time {
+	var poslist []time.Time
+	for _, pos := range iterator.ii.rrule.bysetpos {
+		var daypos, timepos int
+		if pos < 0 {
+			daypos, timepos = divmod(pos, len(iterator.timeset))
 		} else {
-			for _, day := range dayset {
-				if !day.Defined {
-					continue
-				}
-				i := day.Int
-				dateYear, dateMonth, dateDay := iterator.ii.firstyday.AddDate(0, 0, i).Date()
-				for _, timeTemp := range iterator.timeset {
-					tempHour, tempMinute, tempSecond := timeTemp.Clock()
-					res := time.Date(dateYear, dateMonth, dateDay,
-						tempHour, tempMinute, tempSecond,
-						timeTemp.Nanosecond(), timeTemp.Location())
-					if !r.until.IsZero() && res.After(r.until) {
-						r.len = iterator.total
-						iterator.finished = true
-						return
-					} else if !res.Before(r.dtstart) {
-						iterator.total++
-						iterator.remain.Append(res)
-						if iterator.count != 0 {
-							iterator.count--
-							if iterator.count == 0 {
-								r.len = iterator.total
-								iterator.finished = true
-								return
-							}
-						}
-					}
-				}
+			daypos, timepos = divmod(pos-1, len(iterator.timeset))
+		}
+		var temp []int
+		for _, day := range iterator.dayset {
+			if day.Defined {
+				temp = append(temp, day.Int)
 			}
 		}
-		// Handle frequency and interval
-		fixday := false
-		if r.freq == YEARLY {
-			iterator.year += r.interval
+		i, err := pySubscript(temp, daypos)
+		if err != nil {
+			continue
+		}
+		res := iterator.candidate(i, iterator.timeset[timepos])
+		if !timeContains(poslist, res) {
+			poslist = append(poslist, res)
+		}
+	}
+	sort.Sort(timeSlice(poslist))
+	return poslist
+}
+
+// emit outputs res if it is within DTSTART and UNTIL, and reports
+// whether the recurrence goes on after it.
+func (iterator *rIterator) emit(res time.Time) bool {
+	r := iterator.ii.rrule
+	if !r.until.IsZero() && res.After(r.until) {
+		r.len = iterator.total
+		iterator.finished = true
+		return false
+	} else if !res.Before(r.dtstart) {
+		iterator.total++
+		iterator.remain.Append(res)
+		if iterator.count != 0 {
+			iterator.count--
+			if iterator.count == 0 {
+				r.len = iterator.total
+				iterator.finished = true
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// advance moves the iterator to the next period according to the frequency and interval.
+func (iterator *rIterator) advance(filtered bool) {
+	r := iterator.ii.rrule
+	fixday := false
+	if r.freq == YEARLY {
+		iterator.year += r.interval
+		if iterator.year > MAXYEAR {
+			r.len = iterator.total
+			iterator.finished = true
+			return
+		}
+		iterator.ii.rebuild(iterator.year, iterator.month)
+	} else if r.freq == MONTHLY {
+		iterator.month += time.Month(r.interval)
+		if iterator.month > 12 {
+			div, mod := divmod(int(iterator.month), 12)
+			iterator.month = time.Month(mod)
+			iterator.year += div
+			if iterator.month == 0 {
+				iterator.month = 12
+				iterator.year--
+			}
 			if iterator.year > MAXYEAR {
 				r.len = iterator.total
 				iterator.finished = true
 				return
 			}
-			iterator.ii.rebuild(iterator.year, iterator.month)
-		} else if r.freq == MONTHLY {
-			iterator.month += time.Month(r.interval)
-			if iterator.month > 12 {
-				div, mod := divmod(int(iterator.month), 12)
-				iterator.month = time.Month(mod)
-				iterator.year += div
-				if iterator.month == 0 {
-					iterator.month = 12
-					iterator.year--
-				}
-				if iterator.year > MAXYEAR {
-					r.len = iterator.total
-					iterator.finished = true
-					return
-				}
+		}
+		iterator.ii.rebuild(iterator.year, iterator.month)
+	} else if r.freq == WEEKLY {
+		if r.wkst > iterator.weekday {
+			iterator.day += -(iterator.weekday + 1 + (6 - r.wkst)) + r.interval*7
+		} else {
+			iterator.day += -(iterator.weekday - r.wkst) + r.interval*7
+		}
+		iterator.weekday = r.wkst
+		fixday = true
+	} else if r.freq == DAILY {
+		iterator.day += r.interval
+		fixday = true
+	} else if r.freq == HOURLY {
+		if filtered {
+			// Jump to one iteration before next day
+			iterator.hour += ((23 - iterator.hour) / r.interval) * r.interval
+		}
+		for {
+			iterator.hour += r.interval
+			div, mod := divmod(iterator.hour, 24)
+			if div != 0 {
+				iterator.hour = mod
+				iterator.day += div
+				fixday = true
 			}
-			iterator.ii.rebuild(iterator.year, iterator.month)
-		} else if r.freq == WEEKLY {
-			if r.wkst > iterator.weekday {
-				iterator.day += -(iterator.weekday + 1 + (6 - r.wkst)) + r.interval*7
-			} else {
-				iterator.day += -(iterator.weekday - r.wkst) + r.interval*7
+			if len(r.byhour) == 0 || contains(r.byhour, iterator.hour) {
+				break
 			}
-			iterator.weekday = r.wkst
-			fixday = true
-		} else if r.freq == DAILY {
-			iterator.day += r.interval
-			fixday = true
-		} else if r.freq == HOURLY {
-			if filtered {
-				// Jump to one iteration before next day
-				iterator.hour += ((23 - iterator.hour) / r.interval) * r.interval
+			if !iterator.enterPeriod() {
+				return
 			}
-			for {
-				iterator.hour += r.interval
-				div, mod := divmod(iterator.hour, 24)
+		}
+		iterator.ii.fillTimeSet(&iterator.timeset, r.freq, iterator.hour, iterator.minute, iterator.second)
+	} else if r.freq == MINUTELY {
+		if filtered {
+			// Jump to one iteration before next day
+			iterator.minute += ((1439 - (iterator.hour*60 + iterator.minute)) / r.interval) * r.interval
+		}
+		for {
+			iterator.minute += r.interval
+			div, mod := divmod(iterator.minute, 60)
+			if div != 0 {
+				iterator.minute = mod
+				iterator.hour += div
+				div, mod = divmod(iterator.hour, 24)
 				if div != 0 {
 					iterator.hour = mod
 					iterator.day += div
 					fixday = true
 				}
-				if len(r.byhour) == 0 || contains(r.byhour, iterator.hour) {
-					break
-				}
 			}
-			iterator.ii.fillTimeSet(&iterator.timeset, r.freq, iterator.hour, iterator.minute, iterator.second)
-		} else if r.freq == MINUTELY {
-			if filtered {
-				// Jump to one iteration before next day
-				iterator.minute += ((1439 - (iterator.hour*60 + iterator.minute)) / r.interval) * r.interval
+			if (len(r.byhour) == 0 || contains(r.byhour, iterator.hour)) &&
+				(len(r.byminute) == 0 || contains(r.byminute, iterator.minute)) {
+				break
 			}
-			for {
-				iterator.minute += r.interval
-				div, mod := divmod(iterator.minute, 60)
+			if !iterator.enterPeriod() {
+				return
+			}
+		}
+		iterator.ii.fillTimeSet(&iterator.timeset, r.freq, iterator.hour, iterator.minute, iterator.second)
+	} else if r.freq == SECONDLY {
+		if filtered {
+			// Jump to one iteration before next day
+			iterator.second += (((86399 - (iterator.hour*3600 + iterator.minute*60 + iterator.second)) / r.interval) * r.interval)
+		}
+		for {
+			iterator.second += r.interval
+			div, mod := divmod(iterator.second, 60)
+			if div != 0 {
+				iterator.second = mod
+				iterator.minute += div
+				div, mod = divmod(iterator.minute, 60)
 				if div != 0 {
 					iterator.minute = mod
 					iterator.hour += div
@@ -765,62 +864,36 @@ func (iterator *rIterator) generate() {
 						fixday = true
 					}
 				}
-				if (len(r.byhour) == 0 || contains(r.byhour, iterator.hour)) &&
-					(len(r.byminute) == 0 || contains(r.byminute, iterator.minute)) {
-					break
-				}
 			}
-			iterator.ii.fillTimeSet(&iterator.timeset, r.freq, iterator.hour, iterator.minute, iterator.second)
-		} else if r.freq == SECONDLY {
-			if filtered {
-				// Jump to one iteration before next day
-				iterator.second += (((86399 - (iterator.hour*3600 + iterator.minute*60 + iterator.second)) / r.interval) * r.interval)
+			if (len(r.byhour) == 0 || contains(r.byhour, iterator.hour)) &&
+				(len(r.byminute) == 0 || contains(r.byminute, iterator.minute)) &&
+				(len(r.bysecond) == 0 || contains(r.bysecond, iterator.second)) {
+				break
 			}
-			for {
-				iterator.second += r.interval
-				div, mod := divmod(iterator.second, 60)
-				if div != 0 {
-					iterator.second = mod
-					iterator.minute += div
-					div, mod = divmod(iterator.minute, 60)
-					if div != 0 {
-						iterator.minute = mod
-						iterator.hour += div
-						div, mod = divmod(iterator.hour, 24)
-						if div != 0 {
-							iterator.hour = mod
-							iterator.day += div
-							fixday = true
-						}
-					}
-				}
-				if (len(r.byhour) == 0 || contains(r.byhour, iterator.hour)) &&
-					(len(r.byminute) == 0 || contains(r.byminute, iterator.minute)) &&
-					(len(r.bysecond) == 0 || contains(r.bysecond, iterator.second)) {
-					break
-				}
+			if !iterator.enterPeriod() {
+				return
 			}
-			iterator.ii.fillTimeSet(&iterator.timeset, r.freq, iterator.hour, iterator.minute, iterator.second)
 		}
-		if fixday && iterator.day > 28 {
-			daysinmonth := daysIn(iterator.month, iterator.year)
-			if iterator.day > daysinmonth {
-				for iterator.day > daysinmonth {
-					iterator.day -= daysinmonth
-					iterator.month++
-					if iterator.month == 13 {
-						iterator.month = 1
-						iterator.year++
-						if iterator.year > MAXYEAR {
-							r.len = iterator.total
-							iterator.finished = true
-							return
-						}
+		iterator.ii.fillTimeSet(&iterator.timeset, r.freq, iterator.hour, iterator.minute, iterator.second)
+	}
+	if fixday && iterator.day > 28 {
+		daysinmonth := daysIn(iterator.month, iterator.year)
+		if iterator.day > daysinmonth {
+			for iterator.day > daysinmonth {
+				iterator.day -= daysinmonth
+				iterator.month++
+				if iterator.month == 13 {
+					iterator.month = 1
+					iterator.year++
+					if iterator.year > MAXYEAR {
+						r.len = iterator.total
+						iterator.finished = true
+						return
 					}
-					daysinmonth = daysIn(iterator.month, iterator.year)
 				}
-				iterator.ii.rebuild(iterator.year, iterator.month)
+				daysinmonth = daysIn(iterator.month, iterator.year)
 			}
+			iterator.ii.rebuild(iterator.year, iterator.month)
 		}
 	}
 }
@@ -879,7 +952,11 @@ func (s *reusingRemainSlice) Pop() (ret time.Time, ok bool) {
 
 // Iterator return an iterator for RRule
 func (r *RRule) Iterator() Next {
-	iterator := rIterator{}
+	return r.iterator(context.Background()).next
+}
+
+func (r *RRule) iterator(ctx context.Context) *rIterator {
+	iterator := &rIterator{ctx: ctx, done: ctx.Done()}
 	iterator.year, iterator.month, iterator.day = r.dtstart.Date()
 	iterator.hour, iterator.minute, iterator.second = r.dtstart.Clock()
 	iterator.weekday = toPyWeekday(r.dtstart.Weekday())
@@ -899,7 +976,7 @@ func (r *RRule) Iterator() Next {
 		}
 	}
 	iterator.count = r.count
-	return iterator.next
+	return iterator
 }
 
 // All returns all occurrences of the RRule.
@@ -939,7 +1016,7 @@ func (r *RRule) After(dt time.Time, inc bool) time.Time {
 // Default to `time.Now().UTC().Truncate(time.Second)`.
 func (r *RRule) DTStart(dt time.Time) {
 	r.OrigOptions.Dtstart = dt.Truncate(time.Second)
-	*r = buildRRule(r.OrigOptions)
+	r.rebuild()
 }
 
 // GetDTStart gets DTSTART time for rrule
@@ -952,10 +1029,31 @@ func (r *RRule) GetDTStart() time.Time {
 // Default to `Dtstart.Add(time.Duration(1<<63 - 1))`, approximately 290 years.
 func (r *RRule) Until(ut time.Time) {
 	r.OrigOptions.Until = ut.Truncate(time.Second)
-	*r = buildRRule(r.OrigOptions)
+	r.rebuild()
 }
 
 // GetUntil gets UNTIL time for rrule
 func (r *RRule) GetUntil() time.Time {
 	return r.until
 }
+
+// SetMaxPeriods limits the number of candidate periods (years, months, weeks,
+// days, hours, minutes or seconds depending on the frequency) a single expansion
+// of the rule may examine. Rules whose filters rarely or never match can otherwise
+// loop until MAXYEAR. Iterators stop once the budget is exhausted, and the
+// context-aware variants report an *ExpansionError wrapping ErrMaxPeriods.
+// Zero, the default, means no limit.
+func (r *RRule) SetMaxPeriods(n int) {
+	if n < 0 {
+		n = 0
+	}
+	r.maxPeriods = n
+}
+
+// rebuild recalculates the rule from OrigOptions, keeping the settings
+// that are not part of the options.
+func (r *RRule) rebuild() {
+	maxPeriods, duration := r.maxPeriods, r.duration
+	*r = buildRRule(r.OrigOptions, r.clock)
+	r.maxPeriods, r.duration = maxPeriods, duration
+}
@@ -3,6 +3,7 @@
 package rrule
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"time"
@@ -10,10 +11,12 @@ import (
 
 // Set allows more complex recurrence setups, mixing multiple rules, dates, exclusion rules, and exclusion dates
 type Set struct {
-	dtstart time.Time
-	rrule   *RRule
-	rdate   []time.Time
-	exdate  []time.Time
+	dtstart   time.Time
+	rrule     *RRule
+	rdate     []time.Time
+	exdate    []time.Time
+	duration  time.Duration
+	overrides []Override
 }
 
 // Recurrence returns a slice of all the recurrence rules for a set
@@ -56,12 +59,16 @@ func (set *Set) GetDTStart() time.Time {
 
 // RRule set the RRULE for set.
 // There is the only one RRULE in the set as https://tools.ietf.org/html/rfc5545#appendix-A.1
+// The set takes the duration of the rule if it has none.
 func (set *Set) RRule(rrule *RRule) {
 	if !rrule.OrigOptions.Dtstart.IsZero() {
 		set.dtstart = rrule.dtstart
 	} else if !set.dtstart.IsZero() {
 		rrule.DTStart(set.dtstart)
 	}
+	if set.duration == 0 {
+		set.duration = rrule.duration
+	}
 	set.rrule = rrule
 }
 
@@ -112,65 +119,63 @@ func (set *Set) GetExDate() []time.Time {
 	return set.exdate
 }
 
-type genItem struct {
-	dt  time.Time
-	gen Next
+// Iterator returns an iterator for rrule.Set.
+// Occurrences modified by an override are generated at their effective time.
+func (set *Set) Iterator() (next func() (time.Time, bool)) {
+	next, _ = set.iterator(context.Background())
+	return next
 }
 
-type genItemSlice []genItem
-
-func (s genItemSlice) Len() int           { return len(s) }
-func (s genItemSlice) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
-func (s genItemSlice) Less(i, j int) bool { return s[i].dt.Before(s[j].dt) }
+func (set *Set) iterator(ctx context.Context) (Next, func() error) {
+	occurrences, err := set.occurrences(ctx)
+	return func() (time.Time, bool) {
+		o, ok := occurrences()
+		return o.Time, ok
+	}, err
+}
 
-func addGenList(genList *[]genItem, next Next) {
-	dt, ok := next()
-	if ok {
-		*genList = append(*genList, genItem{dt, next})
+// baseIterator returns an iterator over the occurrences of the RRULE and the RDATEs
+// that are not EXDATEs, before overrides are applied.
+func (set *Set) baseIterator(ctx context.Context) (func() (Occurrence, bool), func() error) {
+	var rule Next
+	err := func() error { return nil }
+	if set.rrule != nil {
+		rule, err = set.rrule.IteratorContext(ctx)
 	}
+	sort.Sort(timeSlice(set.rdate))
+	return set.mergeBase(rule, 0, set.rdate), err
 }
 
-// Iterator returns an iterator for rrule.Set
-func (set *Set) Iterator() (next func() (time.Time, bool)) {
-	rlist := []genItem{}
-	exlist := []genItem{}
-
-	sort.Sort(timeSlice(set.rdate))
-	addGenList(&rlist, timeSliceIterator(set.rdate))
-	if set.rrule != nil {
-		addGenList(&rlist, set.rrule.Iterator())
+// mergeBase merges the occurrences of rule, if not nil, whose first one has the index first
+// in the RRULE, with the sorted rdates, and removes the EXDATEs.
+func (set *Set) mergeBase(rule Next, first int, rdates []time.Time) func() (Occurrence, bool) {
+	rlist, exlist := merger{}, merger{}
+	rlist.add(timeSliceIterator(rdates), SourceRDate)
+	if rule != nil {
+		rlist.add(rule, SourceRule)
 	}
-	sort.Sort(genItemSlice(rlist))
 
 	sort.Sort(timeSlice(set.exdate))
-	addGenList(&exlist, timeSliceIterator(set.exdate))
-	sort.Sort(genItemSlice(exlist))
+	exlist.add(timeSliceIterator(set.exdate), SourceRDate)
 
 	lastdt := time.Time{}
-	return func() (time.Time, bool) {
-		for len(rlist) != 0 {
-			dt := rlist[0].dt
-			var ok bool
-			rlist[0].dt, ok = rlist[0].gen()
-			if !ok {
-				rlist = rlist[1:]
-			}
-			sort.Sort(genItemSlice(rlist))
+	return func() (Occurrence, bool) {
+		for !rlist.empty() {
+			item := rlist.pop()
+			dt := item.dt
 			if lastdt.IsZero() || !lastdt.Equal(dt) {
-				for len(exlist) != 0 && exlist[0].dt.Before(dt) {
-					exlist[0].dt, ok = exlist[0].gen()
-					if !ok {
-						exlist = exlist[1:]
-					}
-					sort.Sort(genItemSlice(exlist))
-				}
+				exlist.skipBefore(dt)
 				lastdt = dt
-				if len(exlist) == 0 || !dt.Equal(exlist[0].dt) {
-					return dt, true
+				if exlist.empty() || !dt.Equal(exlist.peek()) {
+					o := Occurrence{Time: dt, RecurrenceID: dt, Source: item.source, Rule: -1, Index: -1}
+					if o.Source == SourceRule {
+						o.Rule, o.Index = 0, first+item.index
+					}
+					return o, true
 				}
 			}
 		}
-		return time.Time{}, false
+		return Occurrence{}, false
 	}
 }
 
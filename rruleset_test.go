@@ -381,3 +381,17 @@ func TestRuleSetChangeDTStartTimezoneRespected(t *testing.T) {
 		}
 	}
 }
+
+func BenchmarkSetIterator(b *testing.B) {
+	r, _ := NewRRule(ROption{Freq: HOURLY, Count: 10000, Dtstart: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)})
+	set := Set{}
+	set.RRule(r)
+	for i := 0; i < 1000; i++ {
+		set.RDate(time.Date(2000, 1, 1, 0, 30, 0, 0, time.UTC).Add(time.Duration(i) * 7 * time.Hour))
+		set.ExDate(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(i) * 5 * time.Hour))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = all(set.Iterator())
+	}
+}
@@ -0,0 +1,41 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package scheduler
+
+import "time"
+
+// Missed are the occurrences of a recurrence missed since the last run.
+type Missed struct {
+	// Times are the missed occurrences to run, according to the policy and the limit.
+	Times []time.Time
+	// Count is the number of missed occurrences, whether they are run or not.
+	Count int
+	// Next is the first occurrence after now, the zero time if there is none.
+	Next time.Time
+}
+
+// CatchUp returns the occurrences t of src with last < t <= now, that were missed
+// by a job whose last successful run was at last, filtered by policy.
+// With RunAll, at most limit occurrences are kept, the latest ones, if limit is positive.
+// It also returns the next occurrence after now, when the job is next due.
+func CatchUp(src Source, last, now time.Time, policy Policy, limit int) Missed {
+	var missed Missed
+	if now.After(last) {
+		for _, t := range src.Between(last, now, true) {
+			if t.After(last) {
+				missed.Times = append(missed.Times, t)
+			}
+		}
+	}
+	missed.Count = len(missed.Times)
+	switch {
+	case policy == Skip:
+		missed.Times = nil
+	case policy == RunLatest && missed.Count != 0:
+		missed.Times = missed.Times[missed.Count-1:]
+	case limit > 0 && missed.Count > limit:
+		missed.Times = missed.Times[missed.Count-limit:]
+	}
+	missed.Next = src.After(now, false)
+	return missed
+}
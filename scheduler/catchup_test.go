@@ -0,0 +1,53 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCatchUp(t *testing.T) {
+	last := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC)
+	next := time.Date(2024, 1, 6, 9, 0, 0, 0, time.UTC)
+	all := []time.Time{
+		time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 5, 9, 0, 0, 0, time.UTC),
+	}
+	for _, c := range []struct {
+		policy Policy
+		limit  int
+		want   []time.Time
+	}{
+		{RunAll, 0, all},
+		{RunAll, 2, all[2:]},
+		{RunLatest, 2, all[3:]},
+		{Skip, 0, nil},
+	} {
+		missed := CatchUp(daily(t), last, now, c.policy, c.limit)
+		if !timesEqual(missed.Times, c.want) || missed.Count != 4 || !missed.Next.Equal(next) {
+			t.Errorf("policy %v, limit %v: get %+v, want %v", c.policy, c.limit, missed, c.want)
+		}
+	}
+
+	missed := CatchUp(daily(t), now, last, RunAll, 0)
+	if len(missed.Times) != 0 || missed.Count != 0 {
+		t.Errorf("get %+v, want nothing missed", missed)
+	}
+}
+
+func TestScheduleLimit(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 1, 5, 8, 0, 0, 0, time.UTC))
+	rec := &recorder{}
+	s := Schedule(daily(t), rec.handle, WithClock(clock), WithLimit(1),
+		WithStart(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	<-clock.sleeping
+	s.Stop()
+	want := []time.Time{time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC)}
+	if value := rec.get(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
@@ -0,0 +1,196 @@
+// 2017-2022, Teambition. All rights reserved.
+
+// Package scheduler runs handlers at the occurrences of recurrences,
+// such as an rrule.RRule or an rrule.Set.
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+// Source is a recurrence to schedule, such as *rrule.RRule or *rrule.Set.
+type Source interface {
+	After(dt time.Time, inc bool) time.Time
+	Between(after, before time.Time, inc bool) []time.Time
+}
+
+// Clock tells the time and waits for durations, it can be replaced to test
+// schedules deterministically.
+type Clock interface {
+	rrule.Clock
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Policy denotes what happens to the occurrences missed while the scheduler could not run,
+// for example because the machine was suspended or the clock jumped forward.
+type Policy int
+
+// Policies
+const (
+	// RunAll runs the handler for every missed occurrence, in order.
+	RunAll Policy = iota
+	// RunLatest runs the handler once, for the latest missed occurrence,
+	// unless an occurrence is due on time.
+	RunLatest
+	// Skip does not run the handler for missed occurrences.
+	Skip
+)
+
+// Handler is called at each occurrence with the time of the occurrence.
+type Handler func(t time.Time)
+
+// Option configures a Scheduler.
+type Option func(*Scheduler)
+
+// WithClock sets the clock of the scheduler, the system clock by default.
+func WithClock(clock Clock) Option {
+	return func(s *Scheduler) { s.clock = clock }
+}
+
+// WithPolicy sets the policy for missed occurrences, RunAll by default.
+func WithPolicy(policy Policy) Option {
+	return func(s *Scheduler) { s.policy = policy }
+}
+
+// WithLimit sets the maximum number of missed occurrences run with RunAll,
+// the latest ones, see CatchUp. Zero, the default, means no limit.
+func WithLimit(n int) Option {
+	return func(s *Scheduler) { s.limit = n }
+}
+
+// WithTolerance sets how late an occurrence may be handled before it is considered missed,
+// one minute by default.
+func WithTolerance(d time.Duration) Option {
+	return func(s *Scheduler) { s.tolerance = d }
+}
+
+// WithMaxSleep sets how long the scheduler sleeps at most before looking at the clock again,
+// one minute by default. Timers measure elapsed time, so the scheduler would otherwise
+// not notice that the wall clock jumped, for example after a suspend or a clock adjustment.
+func WithMaxSleep(d time.Duration) Option {
+	return func(s *Scheduler) { s.maxSleep = d }
+}
+
+// WithStart sets the time after which occurrences are handled, the time of Schedule by default.
+// Earlier start times let the scheduler catch up with the occurrences missed since then.
+func WithStart(t time.Time) Option {
+	return func(s *Scheduler) { s.last = t }
+}
+
+// Scheduler runs a handler at each occurrence of a Source.
+type Scheduler struct {
+	clock     Clock
+	policy    Policy
+	limit     int
+	tolerance time.Duration
+	maxSleep  time.Duration
+	handler   Handler
+
+	src     Source
+	last    time.Time
+	replace chan Source
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Schedule starts running handler at each occurrence of src after now, one at a time,
+// until Stop is called.
+func Schedule(src Source, handler Handler, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		clock:     realClock{},
+		policy:    RunAll,
+		tolerance: time.Minute,
+		maxSleep:  time.Minute,
+		handler:   handler,
+		src:       src,
+		replace:   make(chan Source),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.last.IsZero() {
+		s.last = s.clock.Now()
+	}
+	go s.run()
+	return s
+}
+
+// Replace replaces the recurrence of the scheduler, for example after the rule was edited.
+// The occurrences of the new recurrence up to the last time the scheduler looked at
+// the clock are not handled.
+func (s *Scheduler) Replace(src Source) {
+	select {
+	case s.replace <- src:
+	case <-s.done:
+	}
+}
+
+// Stop stops the scheduler, and waits for the running handler, if any, to return.
+func (s *Scheduler) Stop() {
+	s.once.Do(func() { close(s.stop) })
+	<-s.done
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+	for {
+		now := s.clock.Now()
+		next := s.fire(now)
+
+		var wake <-chan time.Time
+		if !next.IsZero() {
+			d := next.Sub(now)
+			if s.maxSleep > 0 && d > s.maxSleep {
+				d = s.maxSleep
+			}
+			wake = s.clock.After(d)
+		} else if s.maxSleep > 0 {
+			wake = s.clock.After(s.maxSleep)
+		}
+
+		select {
+		case <-wake:
+		case s.src = <-s.replace:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// fire handles the occurrences due since the last time the scheduler looked at the clock,
+// and returns the next occurrence.
+func (s *Scheduler) fire(now time.Time) time.Time {
+	if !now.After(s.last) {
+		// The clock went backwards, the occurrences up to last were already handled.
+		return s.src.After(s.last, false)
+	}
+	cutoff := now.Add(-s.tolerance)
+	if cutoff.Before(s.last) {
+		cutoff = s.last
+	}
+	missed := CatchUp(s.src, s.last, cutoff, s.policy, s.limit)
+	due := CatchUp(s.src, cutoff, now, RunAll, 0)
+	s.last = now
+
+	if s.policy == RunLatest && len(due.Times) != 0 {
+		missed.Times = nil
+	}
+	for _, t := range missed.Times {
+		s.handler(t)
+	}
+	for _, t := range due.Times {
+		s.handler(t)
+	}
+	return due.Next
+}
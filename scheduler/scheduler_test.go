@@ -0,0 +1,195 @@
+// 2017-2022, Teambition. All rights reserved.
+
+package scheduler
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// fakeClock only moves when advanced, and reports on sleeping each time the scheduler sleeps.
+type fakeClock struct {
+	mu       sync.Mutex
+	now      time.Time
+	timers   []fakeTimer
+	sleeping chan struct{}
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, sleeping: make(chan struct{}, 100)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	c.timers = append(c.timers, fakeTimer{c.now.Add(d), ch})
+	c.sleeping <- struct{}{}
+	return ch
+}
+
+// Set moves the clock to now and fires the timers that expired,
+// or all the timers if wake is true.
+func (c *fakeClock) Set(now time.Time, wake bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+	timers := c.timers[:0]
+	for _, timer := range c.timers {
+		if !wake && timer.at.After(now) {
+			timers = append(timers, timer)
+		} else {
+			timer.c <- now
+		}
+	}
+	c.timers = timers
+}
+
+type recorder struct {
+	mu    sync.Mutex
+	times []time.Time
+}
+
+func (r *recorder) handle(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.times = append(r.times, t)
+}
+
+func (r *recorder) get() []time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]time.Time(nil), r.times...)
+}
+
+func daily(t *testing.T) *rrule.RRule {
+	r, err := rrule.NewRRule(rrule.ROption{Freq: rrule.DAILY, Dtstart: time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// step moves the clock forward and waits for the scheduler to sleep again.
+func step(clock *fakeClock, now time.Time) {
+	clock.Set(now, false)
+	<-clock.sleeping
+}
+
+func timesEqual(value, want []time.Time) bool {
+	if len(value) != len(want) {
+		return false
+	}
+	for i := range value {
+		if !value[i].Equal(want[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSchedule(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC))
+	rec := &recorder{}
+	s := Schedule(daily(t), rec.handle, WithClock(clock), WithMaxSleep(24*time.Hour))
+	defer s.Stop()
+	<-clock.sleeping
+
+	step(clock, time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))
+	step(clock, time.Date(2024, 1, 2, 9, 0, 1, 0, time.UTC))
+	// The clock going backwards does not fire occurrences twice.
+	clock.Set(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC), true)
+	<-clock.sleeping
+	clock.Set(time.Date(2024, 1, 2, 9, 0, 1, 0, time.UTC), true)
+	<-clock.sleeping
+	want := []time.Time{
+		time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+	}
+	if value := rec.get(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	for _, c := range []struct {
+		policy Policy
+		want   []time.Time
+	}{
+		{RunAll, []time.Time{
+			time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC),
+			time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC),
+		}},
+		{RunLatest, []time.Time{time.Date(2024, 1, 3, 9, 0, 0, 0, time.UTC)}},
+		{Skip, nil},
+	} {
+		clock := newFakeClock(time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC))
+		rec := &recorder{}
+		s := Schedule(daily(t), rec.handle, WithClock(clock), WithPolicy(c.policy))
+		<-clock.sleeping
+		// The machine was suspended, the clock jumps.
+		step(clock, time.Date(2024, 1, 3, 12, 0, 0, 0, time.UTC))
+		s.Stop()
+		if value := rec.get(); !timesEqual(value, c.want) {
+			t.Errorf("policy %v: get %v, want %v", c.policy, value, c.want)
+		}
+	}
+}
+
+func TestReplace(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC))
+	rec := &recorder{}
+	s := Schedule(daily(t), rec.handle, WithClock(clock))
+	<-clock.sleeping
+
+	r, _ := rrule.NewRRule(rrule.ROption{Freq: rrule.HOURLY, Count: 3, Dtstart: time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC)})
+	s.Replace(r)
+	<-clock.sleeping
+	for _, now := range []time.Time{
+		time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+	} {
+		step(clock, now)
+	}
+	s.Stop()
+	want := []time.Time{
+		time.Date(2024, 1, 1, 8, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 9, 30, 0, 0, time.UTC),
+		time.Date(2024, 1, 1, 10, 30, 0, 0, time.UTC),
+	}
+	if value := rec.get(); !timesEqual(value, want) {
+		t.Errorf("get %v, want %v", value, want)
+	}
+	// Replace and Stop do not block once stopped.
+	s.Replace(r)
+	s.Stop()
+}
+
+func TestMaxSleep(t *testing.T) {
+	clock := newFakeClock(time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC))
+	rec := &recorder{}
+	s := Schedule(daily(t), rec.handle, WithClock(clock), WithMaxSleep(10*time.Minute))
+	defer s.Stop()
+	<-clock.sleeping
+	// The scheduler wakes up after at most 10 minutes, the occurrence is not due yet.
+	step(clock, time.Date(2024, 1, 1, 8, 10, 0, 0, time.UTC))
+	if value := rec.get(); len(value) != 0 {
+		t.Errorf("get %v, want nothing", value)
+	}
+}
@@ -20,6 +20,9 @@ const (
 )
 
 func timeToStr(time time.Time) string {
+	if IsFloating(time) {
+		return time.Format(LocalDateTimeFormat)
+	}
 	return time.UTC().Format(DateTimeFormat)
 }
 
@@ -98,11 +101,15 @@ func appendIntsOption(options []string, key string, value []int) []string {
 	if len(value) == 0 {
 		return options
 	}
+	return append(options, fmt.Sprintf("%s=%s", key, joinInts(value)))
+}
+
+func joinInts(value []int) string {
 	valueStr := make([]string, len(value))
 	for i, v := range value {
 		valueStr[i] = strconv.Itoa(v)
 	}
-	return append(options, fmt.Sprintf("%s=%s", key, strings.Join(valueStr, ",")))
+	return strings.Join(valueStr, ",")
 }
 
 func strToInts(value string) ([]int, error) {
@@ -276,35 +283,37 @@ func (set *Set) String() string {
 	return strings.Join(res, "\n")
 }
 
-// StrToRRule converts string to RRule
-func StrToRRule(rfcString string) (*RRule, error) {
+// StrToRRule converts string to RRule.
+// Options are those of NewRRule.
+func StrToRRule(rfcString string, opts ...Option) (*RRule, error) {
 	option, e := StrToROption(rfcString)
 	if e != nil {
 		return nil, e
 	}
-	return NewRRule(*option)
+	return NewRRule(*option, opts...)
 }
 
-// StrToRRuleSet converts string to RRuleSet
-func StrToRRuleSet(s string) (*Set, error) {
+// StrToRRuleSet converts string to RRuleSet.
+// Options are those of NewRRule, applied to the RRULE.
+func StrToRRuleSet(s string, opts ...Option) (*Set, error) {
 	s = strings.TrimSpace(s)
 	if s == "" {
 		return nil, errors.New("empty string")
 	}
 	ss := strings.Split(s, "\n")
-	return StrSliceToRRuleSet(ss)
+	return StrSliceToRRuleSet(ss, opts...)
 }
 
 // StrSliceToRRuleSet converts given str slice to RRuleSet
 // In case there is a time met in any rule without specified time zone, when
 // it is parsed in UTC (see StrSliceToRRuleSetInLoc)
-func StrSliceToRRuleSet(ss []string) (*Set, error) {
-	return StrSliceToRRuleSetInLoc(ss, time.UTC)
+func StrSliceToRRuleSet(ss []string, opts ...Option) (*Set, error) {
+	return StrSliceToRRuleSetInLoc(ss, time.UTC, opts...)
 }
 
 // StrSliceToRRuleSetInLoc is same as StrSliceToRRuleSet, but by default parses local times
 // in specified default location
-func StrSliceToRRuleSetInLoc(ss []string, defaultLoc *time.Location) (*Set, error) {
+func StrSliceToRRuleSetInLoc(ss []string, defaultLoc *time.Location, opts ...Option) (*Set, error) {
 	if len(ss) == 0 {
 		return &Set{}, nil
 	}
@@ -342,9 +351,9 @@ func StrSliceToRRuleSetInLoc(ss []string, defaultLoc *time.Location) (*Set, erro
 			if err != nil {
 				return nil, fmt.Errorf("StrToROption failed: %v", err)
 			}
-			r, err := NewRRule(*rOpt)
+			r, err := NewRRule(*rOpt, opts...)
 			if err != nil {
-				return nil, fmt.Errorf("NewRRule failed: %v", r)
+				return nil, fmt.Errorf("NewRRule failed: %v", err)
 			}
 
 			set.RRule(r)
@@ -371,6 +380,9 @@ func StrSliceToRRuleSetInLoc(ss []string, defaultLoc *time.Location) (*Set, erro
 // DTSTART:19970714T173000Z                      ; UTC time
 // DTSTART;TZID=America/New_York:19970714T133000 ; Local time and time zone reference
 func timeToRFCDatetimeStr(time time.Time) string {
+	if IsFloating(time) {
+		return fmt.Sprintf(":%s", time.Format(LocalDateTimeFormat))
+	}
 	if time.Location().String() != "UTC" {
 		return fmt.Sprintf(";TZID=%s:%s", time.Location().String(), time.Format(LocalDateTimeFormat))
 	}